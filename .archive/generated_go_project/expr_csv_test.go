@@ -0,0 +1,44 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProcessCSVExprWiring(t *testing.T) {
+	dir := t.TempDir()
+	inputPath := filepath.Join(dir, "input.csv")
+	outputPath := filepath.Join(dir, "output.csv")
+
+	if err := os.WriteFile(inputPath, []byte("name,amount\nalice,30\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	app, err := NewAppWithConfig(Config{Expr: "$1 = toupper($1); print"})
+	if err != nil {
+		t.Fatalf("NewAppWithConfig: %v", err)
+	}
+	count, err := app.ProcessCSV(inputPath, outputPath)
+	if err != nil {
+		t.Fatalf("ProcessCSV: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("count = %d, want 1", count)
+	}
+
+	out, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	want := "name,amount\nALICE,30\n"
+	if string(out) != want {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+}
+
+func TestNewAppWithConfigInvalidExpr(t *testing.T) {
+	if _, err := NewAppWithConfig(Config{Expr: "$1 = ("}); err == nil {
+		t.Error("NewAppWithConfig: expected error for invalid --expr, got nil")
+	}
+}
@@ -0,0 +1,361 @@
+// Package charset detects and transcodes the handful of legacy text
+// encodings that show up in CSV exports from Excel and older systems
+// (UTF-16, Windows-1252/Latin-1, GBK, Shift-JIS) so the rest of the
+// pipeline can assume UTF-8. GBK and Shift-JIS decoding is delegated to
+// golang.org/x/text/encoding, which carries the double-byte mapping
+// tables; everything else here is hand-rolled since it's a handful of
+// bytes of logic each.
+package charset
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/transform"
+)
+
+// utf8BOM is the UTF-8 encoding of U+FEFF, the byte-order mark Excel
+// prepends to "UTF-8 with BOM" exports. Every reader returned by
+// NewReader decodes into UTF-8, so stripping it here, once, covers a
+// BOM regardless of which source encoding carried it in.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// Encoding identifies a source or destination text encoding.
+type Encoding int
+
+const (
+	// UTF8 is the default, pass-through encoding.
+	UTF8 Encoding = iota
+	UTF16LE
+	UTF16BE
+	Windows1252
+	GBK
+	ShiftJIS
+)
+
+func (e Encoding) String() string {
+	switch e {
+	case UTF8:
+		return "utf-8"
+	case UTF16LE:
+		return "utf-16le"
+	case UTF16BE:
+		return "utf-16be"
+	case Windows1252:
+		return "windows-1252"
+	case GBK:
+		return "gbk"
+	case ShiftJIS:
+		return "shift-jis"
+	default:
+		return "unknown"
+	}
+}
+
+// Parse maps a --input-encoding/--output-encoding flag value to an
+// Encoding. It accepts common aliases (e.g. "latin1" for Windows-1252).
+func Parse(name string) (Encoding, error) {
+	switch name {
+	case "", "utf-8", "utf8":
+		return UTF8, nil
+	case "utf-16le", "utf16le":
+		return UTF16LE, nil
+	case "utf-16be", "utf16be":
+		return UTF16BE, nil
+	case "windows-1252", "cp1252", "latin-1", "latin1", "iso-8859-1":
+		return Windows1252, nil
+	case "gbk", "gb2312":
+		return GBK, nil
+	case "shift-jis", "shiftjis", "sjis":
+		return ShiftJIS, nil
+	default:
+		return UTF8, fmt.Errorf("charset: unknown encoding %q", name)
+	}
+}
+
+// Detect guesses the encoding of sample, the first few KB of a file. It
+// checks for a byte-order mark first, then falls back to validating the
+// sample as UTF-8, and finally to byte-frequency heuristics that
+// distinguish Windows-1252 text from double-byte CJK encodings.
+func Detect(sample []byte) Encoding {
+	switch {
+	case bytes.HasPrefix(sample, []byte{0xFF, 0xFE}):
+		return UTF16LE
+	case bytes.HasPrefix(sample, []byte{0xFE, 0xFF}):
+		return UTF16BE
+	case bytes.HasPrefix(sample, []byte{0xEF, 0xBB, 0xBF}):
+		return UTF8
+	}
+
+	if utf8.Valid(sample) {
+		return UTF8
+	}
+
+	var highBytes, doubleByteLead int
+	for i, b := range sample {
+		if b < 0x80 {
+			continue
+		}
+		highBytes++
+		// GBK and Shift-JIS lead bytes are almost always in this range;
+		// Windows-1252 text sprinkles high bytes (curly quotes, accents)
+		// far more sparsely and without this lead/trail pairing.
+		if b >= 0x81 && b <= 0xFE && i+1 < len(sample) && sample[i+1] >= 0x40 {
+			doubleByteLead++
+		}
+	}
+	if highBytes == 0 {
+		return UTF8
+	}
+	if float64(doubleByteLead)/float64(highBytes) > 0.5 {
+		// Can't distinguish GBK from Shift-JIS from byte patterns alone
+		// without a real mapping table; GBK is the more common case for
+		// CSV exports and callers can always override with
+		// --input-encoding.
+		return GBK
+	}
+	return Windows1252
+}
+
+// NewReader wraps r, decoding bytes from enc into a UTF-8 stream with
+// any leading byte-order mark stripped.
+func NewReader(r io.Reader, enc Encoding) (io.Reader, error) {
+	var out io.Reader
+	switch enc {
+	case UTF8:
+		out = r
+	case UTF16LE, UTF16BE:
+		out = newUTF16Reader(r, enc)
+	case Windows1252:
+		out = newWindows1252Reader(r)
+	case GBK:
+		out = transform.NewReader(r, simplifiedchinese.GBK.NewDecoder())
+	case ShiftJIS:
+		out = transform.NewReader(r, japanese.ShiftJIS.NewDecoder())
+	default:
+		return nil, fmt.Errorf("charset: unsupported encoding %v", enc)
+	}
+	return newBOMStrippingReader(out), nil
+}
+
+// NewWriter wraps w, encoding the UTF-8 bytes written to it as enc.
+func NewWriter(w io.Writer, enc Encoding) (io.Writer, error) {
+	switch enc {
+	case UTF8:
+		return w, nil
+	case UTF16LE, UTF16BE:
+		return newUTF16Writer(w, enc), nil
+	case Windows1252:
+		return newWindows1252Writer(w), nil
+	case GBK:
+		return transform.NewWriter(w, simplifiedchinese.GBK.NewEncoder()), nil
+	case ShiftJIS:
+		return transform.NewWriter(w, japanese.ShiftJIS.NewEncoder()), nil
+	default:
+		return nil, fmt.Errorf("charset: unsupported encoding %v", enc)
+	}
+}
+
+// bomStrippingReader drops a leading UTF-8 BOM from src, if present, and
+// is otherwise a pass-through. It checks only the first read: a BOM can
+// only ever appear at the very start of a stream.
+type bomStrippingReader struct {
+	src     io.Reader
+	checked bool
+	pending []byte
+}
+
+func newBOMStrippingReader(r io.Reader) io.Reader {
+	return &bomStrippingReader{src: r}
+}
+
+func (r *bomStrippingReader) Read(p []byte) (int, error) {
+	if !r.checked {
+		r.checked = true
+		head := make([]byte, len(utf8BOM))
+		n, err := io.ReadFull(r.src, head)
+		switch {
+		case n == len(utf8BOM) && bytes.Equal(head, utf8BOM):
+			// BOM consumed; fall through to read the rest normally.
+		case err != nil && err != io.ErrUnexpectedEOF:
+			return 0, err
+		default:
+			r.pending = head[:n]
+		}
+	}
+	if len(r.pending) > 0 {
+		n := copy(p, r.pending)
+		r.pending = r.pending[n:]
+		return n, nil
+	}
+	return r.src.Read(p)
+}
+
+type utf16Reader struct {
+	src       io.Reader
+	bigEndian bool
+	pending   []byte
+	leftover  []byte
+}
+
+func newUTF16Reader(r io.Reader, enc Encoding) io.Reader {
+	return &utf16Reader{src: r, bigEndian: enc == UTF16BE}
+}
+
+func (r *utf16Reader) Read(p []byte) (int, error) {
+	for len(r.leftover) == 0 {
+		buf := make([]byte, 4096)
+		n, err := r.src.Read(buf)
+		if n > 0 {
+			r.pending = append(r.pending, buf[:n]...)
+		}
+		// Decode complete uint16 code units, keeping any odd trailing byte.
+		usable := len(r.pending) - (len(r.pending) % 2)
+		if usable > 0 {
+			units := make([]uint16, usable/2)
+			for i := 0; i < usable; i += 2 {
+				if r.bigEndian {
+					units[i/2] = uint16(r.pending[i])<<8 | uint16(r.pending[i+1])
+				} else {
+					units[i/2] = uint16(r.pending[i+1])<<8 | uint16(r.pending[i])
+				}
+			}
+			r.leftover = []byte(string(utf16.Decode(units)))
+			r.pending = r.pending[usable:]
+		}
+		if err != nil {
+			if len(r.leftover) == 0 {
+				return 0, err
+			}
+			break
+		}
+		if len(r.leftover) > 0 {
+			break
+		}
+	}
+	n := copy(p, r.leftover)
+	r.leftover = r.leftover[n:]
+	return n, nil
+}
+
+type utf16Writer struct {
+	dst       io.Writer
+	bigEndian bool
+}
+
+func newUTF16Writer(w io.Writer, enc Encoding) io.Writer {
+	return &utf16Writer{dst: w, bigEndian: enc == UTF16BE}
+}
+
+func (w *utf16Writer) Write(p []byte) (int, error) {
+	units := utf16.Encode(bytes.Runes(p))
+	out := make([]byte, 0, len(units)*2)
+	for _, u := range units {
+		if w.bigEndian {
+			out = append(out, byte(u>>8), byte(u))
+		} else {
+			out = append(out, byte(u), byte(u>>8))
+		}
+	}
+	if _, err := w.dst.Write(out); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// windows1252Extra holds the code points for bytes 0x80-0x9F, the range
+// where Windows-1252 diverges from Latin-1/ISO-8859-1 (which maps those
+// bytes directly to U+0080-U+009F control codes instead).
+var windows1252Extra = [32]rune{
+	0x20AC, 0x81, 0x201A, 0x0192, 0x201E, 0x2026, 0x2020, 0x2021,
+	0x02C6, 0x2030, 0x0160, 0x2039, 0x0152, 0x8D, 0x017D, 0x8F,
+	0x90, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014,
+	0x02DC, 0x2122, 0x0161, 0x203A, 0x0153, 0x9D, 0x017E, 0x0178,
+}
+
+type windows1252Reader struct {
+	src      io.Reader
+	leftover []byte
+	pending  error
+}
+
+func newWindows1252Reader(r io.Reader) io.Reader {
+	return &windows1252Reader{src: r}
+}
+
+// Read decodes Windows-1252 bytes into UTF-8, one source byte expanding
+// to up to three UTF-8 bytes. Since that can produce more decoded bytes
+// than fit in p, undelivered output is kept in leftover (and any error
+// src.Read returned alongside it in pending) and drained on subsequent
+// calls before any new source bytes are read — the same leftover
+// handling utf16Reader.Read uses for the same multi-byte-expansion
+// reason.
+func (r *windows1252Reader) Read(p []byte) (int, error) {
+	if len(r.leftover) == 0 {
+		if r.pending != nil {
+			err := r.pending
+			r.pending = nil
+			return 0, err
+		}
+		raw := make([]byte, len(p))
+		n, err := r.src.Read(raw)
+		if n == 0 {
+			return 0, err
+		}
+		var buf bytes.Buffer
+		for _, b := range raw[:n] {
+			var ru rune
+			if b >= 0x80 && b <= 0x9F {
+				ru = windows1252Extra[b-0x80]
+			} else {
+				ru = rune(b)
+			}
+			buf.WriteRune(ru)
+		}
+		r.leftover = buf.Bytes()
+		r.pending = err
+	}
+	n := copy(p, r.leftover)
+	r.leftover = r.leftover[n:]
+	return n, nil
+}
+
+type windows1252Writer struct {
+	dst io.Writer
+}
+
+func newWindows1252Writer(w io.Writer) io.Writer {
+	return &windows1252Writer{dst: w}
+}
+
+func (w *windows1252Writer) Write(p []byte) (int, error) {
+	out := make([]byte, 0, len(p))
+	for _, ru := range string(p) {
+		if b, ok := toWindows1252Byte(ru); ok {
+			out = append(out, b)
+			continue
+		}
+		out = append(out, '?')
+	}
+	if _, err := w.dst.Write(out); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func toWindows1252Byte(ru rune) (byte, bool) {
+	if ru < 0x80 || (ru >= 0xA0 && ru <= 0xFF) {
+		return byte(ru), true
+	}
+	for i, extra := range windows1252Extra {
+		if extra == ru {
+			return byte(0x80 + i), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,248 @@
+package charset
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+func TestDetectBOMs(t *testing.T) {
+	cases := []struct {
+		name   string
+		sample []byte
+		want   Encoding
+	}{
+		{"utf16le-bom", []byte{0xFF, 0xFE, 'h', 0}, UTF16LE},
+		{"utf16be-bom", []byte{0xFE, 0xFF, 0, 'h'}, UTF16BE},
+		{"utf8-bom", []byte{0xEF, 0xBB, 0xBF, 'h', 'i'}, UTF8},
+		{"plain-ascii", []byte("hello, world"), UTF8},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := Detect(c.sample); got != c.want {
+				t.Errorf("Detect(%q) = %v, want %v", c.sample, got, c.want)
+			}
+		})
+	}
+}
+
+func TestParseRoundTripsString(t *testing.T) {
+	for _, name := range []string{"utf-8", "utf-16le", "utf-16be", "windows-1252", "gbk", "shift-jis"} {
+		enc, err := Parse(name)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", name, err)
+		}
+		if enc.String() == "unknown" {
+			t.Errorf("Parse(%q).String() = unknown", name)
+		}
+	}
+}
+
+func TestParseUnknownEncoding(t *testing.T) {
+	if _, err := Parse("ebcdic"); err == nil {
+		t.Error("Parse(ebcdic): expected error, got nil")
+	}
+}
+
+func TestUTF16ReaderRoundTrip(t *testing.T) {
+	want := "héllo"
+	units := utf16Encode(want)
+	le := make([]byte, 0, len(units)*2)
+	for _, u := range units {
+		le = append(le, byte(u), byte(u>>8))
+	}
+
+	r, err := NewReader(bytes.NewReader(le), UTF16LE)
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if string(got) != want {
+		t.Errorf("decoded = %q, want %q", got, want)
+	}
+}
+
+func TestWindows1252WriterSmartQuotes(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, Windows1252)
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+	if _, err := w.Write([]byte("‘quoted’")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	want := []byte{0x91, 'q', 'u', 'o', 't', 'e', 'd', 0x92}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("encoded = %v, want %v", buf.Bytes(), want)
+	}
+}
+
+func TestWindows1252ReaderRoundTrip(t *testing.T) {
+	src := []byte{0xE9, 0xE9, 0xE9, 'c', 'o', 'l'}
+	r, err := NewReader(bytes.NewReader(src), Windows1252)
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	want := "ééécol"
+	if string(got) != want {
+		t.Errorf("decoded = %q, want %q", got, want)
+	}
+}
+
+// TestWindows1252ReaderSmallBufferDoesNotDropBytes exercises the case a
+// single-byte Read can't return whole: one 0xE9 byte expands to the
+// two-byte UTF-8 encoding of 'é', so a caller reading one byte at a time
+// must get both bytes back across two calls, not lose the second one.
+func TestWindows1252ReaderSmallBufferDoesNotDropBytes(t *testing.T) {
+	src := []byte{0xE9, 0xE9, 0xE9, 'c', 'o', 'l'}
+	r, err := NewReader(bytes.NewReader(src), Windows1252)
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+	var got []byte
+	buf := make([]byte, 1)
+	for {
+		n, err := r.Read(buf)
+		got = append(got, buf[:n]...)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Read: %v", err)
+		}
+	}
+	want := "ééécol"
+	if string(got) != want {
+		t.Errorf("decoded = %q, want %q", got, want)
+	}
+}
+
+func TestGBKAndShiftJISRoundTrip(t *testing.T) {
+	cases := []struct {
+		name string
+		enc  Encoding
+		want string
+	}{
+		{"gbk", GBK, "你好，世界"},
+		{"shift-jis", ShiftJIS, "こんにちは"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := NewWriter(&buf, c.enc)
+			if err != nil {
+				t.Fatalf("NewWriter: %v", err)
+			}
+			if _, err := w.Write([]byte(c.want)); err != nil {
+				t.Fatalf("Write: %v", err)
+			}
+
+			r, err := NewReader(bytes.NewReader(buf.Bytes()), c.enc)
+			if err != nil {
+				t.Fatalf("NewReader: %v", err)
+			}
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("ReadAll: %v", err)
+			}
+			if string(got) != c.want {
+				t.Errorf("decoded = %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestGBKDecodesAgainstReferenceEncoder(t *testing.T) {
+	encoded, err := simplifiedchinese.GBK.NewEncoder().Bytes([]byte("你好"))
+	if err != nil {
+		t.Fatalf("reference encode: %v", err)
+	}
+	r, err := NewReader(bytes.NewReader(encoded), GBK)
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if string(got) != "你好" {
+		t.Errorf("decoded = %q, want %q", got, "你好")
+	}
+}
+
+func TestShiftJISDecodesAgainstReferenceEncoder(t *testing.T) {
+	encoded, err := japanese.ShiftJIS.NewEncoder().Bytes([]byte("ありがとう"))
+	if err != nil {
+		t.Fatalf("reference encode: %v", err)
+	}
+	r, err := NewReader(bytes.NewReader(encoded), ShiftJIS)
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if string(got) != "ありがとう" {
+		t.Errorf("decoded = %q, want %q", got, "ありがとう")
+	}
+}
+
+func TestNewReaderStripsUTF8BOM(t *testing.T) {
+	src := append([]byte{0xEF, 0xBB, 0xBF}, "name,amount\n"...)
+	r, err := NewReader(bytes.NewReader(src), UTF8)
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if string(got) != "name,amount\n" {
+		t.Errorf("decoded = %q, want %q (BOM not stripped)", got, "name,amount\n")
+	}
+}
+
+func TestNewReaderStripsBOMAfterUTF16Decode(t *testing.T) {
+	units := utf16Encode("\ufeffhi")
+	le := make([]byte, 0, len(units)*2)
+	for _, u := range units {
+		le = append(le, byte(u), byte(u>>8))
+	}
+	r, err := NewReader(bytes.NewReader(le), UTF16LE)
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if string(got) != "hi" {
+		t.Errorf("decoded = %q, want %q (BOM not stripped)", got, "hi")
+	}
+}
+
+// utf16Encode is a tiny local helper so the test doesn't need to import
+// unicode/utf16 just to build a fixture.
+func utf16Encode(s string) []uint16 {
+	var out []uint16
+	for _, r := range s {
+		if r <= 0xFFFF {
+			out = append(out, uint16(r))
+			continue
+		}
+		r -= 0x10000
+		out = append(out, uint16(0xD800+(r>>10)), uint16(0xDC00+(r&0x3FF)))
+	}
+	return out
+}
@@ -0,0 +1,78 @@
+package expr
+
+// Program is a parsed --expr script: a flat list of statements executed
+// once per input record.
+type Program struct {
+	Stmts []Stmt
+}
+
+// Stmt is a statement node.
+type Stmt interface{ stmt() }
+
+// ExprStmt evaluates an expression for its side effects (assignments).
+type ExprStmt struct{ X Expr }
+
+// IfStmt is `if (Cond) Then else Else`, Else may be nil.
+type IfStmt struct {
+	Cond       Expr
+	Then, Else []Stmt
+}
+
+// NextStmt aborts the rest of the script and drops the record from output.
+type NextStmt struct{}
+
+// PrintStmt emits the current record. With no Args it prints $0 (the
+// field vector rejoined); with Args it prints them sprintf-joined.
+type PrintStmt struct{ Args []Expr }
+
+func (ExprStmt) stmt()  {}
+func (IfStmt) stmt()    {}
+func (NextStmt) stmt()  {}
+func (PrintStmt) stmt() {}
+
+// Expr is an expression node.
+type Expr interface{ expr() }
+
+type NumberLit struct{ Value float64 }
+type StringLit struct{ Value string }
+type RegexLit struct{ Pattern string }
+
+// FieldRef is $Index; Index is evaluated and truncated to an int. $0
+// refers to the whole record.
+type FieldRef struct{ Index Expr }
+
+// Ident is a bare name: a builtin (NR, NF) or a user-defined variable.
+type Ident struct{ Name string }
+
+// Assign is `Target = Value`; Target is a FieldRef or Ident.
+type Assign struct {
+	Target Expr
+	Value  Expr
+}
+
+type BinaryExpr struct {
+	Op   tokenKind
+	L, R Expr
+}
+
+type UnaryExpr struct {
+	Op tokenKind
+	X  Expr
+}
+
+// Call is a builtin function call: toupper, tolower, substr, split,
+// sprintf, length, match.
+type Call struct {
+	Name string
+	Args []Expr
+}
+
+func (NumberLit) expr()  {}
+func (StringLit) expr()  {}
+func (RegexLit) expr()   {}
+func (FieldRef) expr()   {}
+func (Ident) expr()      {}
+func (Assign) expr()     {}
+func (BinaryExpr) expr() {}
+func (UnaryExpr) expr()  {}
+func (Call) expr()       {}
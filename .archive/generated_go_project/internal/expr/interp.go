@@ -0,0 +1,485 @@
+package expr
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// errNext is a sentinel error used to unwind statement execution when a
+// `next` statement runs; it is never surfaced to callers of Run.
+var errNext = fmt.Errorf("expr: next")
+
+// Interp evaluates a compiled Program once per CSV record. Run itself is
+// safe for concurrent use across goroutines sharing one Interp: all
+// per-record state lives on the stack, and the only shared mutable state
+// (the regex cache, needed for regex literals built dynamically via
+// match()/~ on non-literal patterns) is guarded by regexMu.
+type Interp struct {
+	prog       *Program
+	regexMu    sync.Mutex
+	regexCache map[string]*regexp.Regexp
+}
+
+// NewInterp compiles prog for evaluation, pre-populating the regex cache
+// so per-record matches never pay compilation cost.
+func NewInterp(prog *Program) (*Interp, error) {
+	i := &Interp{prog: prog, regexCache: make(map[string]*regexp.Regexp)}
+	if err := i.precompile(prog.Stmts); err != nil {
+		return nil, err
+	}
+	return i, nil
+}
+
+func (i *Interp) precompile(stmts []Stmt) error {
+	for _, s := range stmts {
+		switch st := s.(type) {
+		case ExprStmt:
+			if err := i.precompileExpr(st.X); err != nil {
+				return err
+			}
+		case IfStmt:
+			if err := i.precompileExpr(st.Cond); err != nil {
+				return err
+			}
+			if err := i.precompile(st.Then); err != nil {
+				return err
+			}
+			if err := i.precompile(st.Else); err != nil {
+				return err
+			}
+		case PrintStmt:
+			for _, a := range st.Args {
+				if err := i.precompileExpr(a); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (i *Interp) precompileExpr(e Expr) error {
+	switch x := e.(type) {
+	case RegexLit:
+		return i.compileRegex(x.Pattern)
+	case BinaryExpr:
+		if err := i.precompileExpr(x.L); err != nil {
+			return err
+		}
+		return i.precompileExpr(x.R)
+	case UnaryExpr:
+		return i.precompileExpr(x.X)
+	case Assign:
+		if err := i.precompileExpr(x.Target); err != nil {
+			return err
+		}
+		return i.precompileExpr(x.Value)
+	case Call:
+		for _, a := range x.Args {
+			if err := i.precompileExpr(a); err != nil {
+				return err
+			}
+		}
+	case FieldRef:
+		return i.precompileExpr(x.Index)
+	}
+	return nil
+}
+
+func (i *Interp) compileRegex(pattern string) error {
+	i.regexMu.Lock()
+	defer i.regexMu.Unlock()
+	if _, ok := i.regexCache[pattern]; ok {
+		return nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("expr: bad regex /%s/: %w", pattern, err)
+	}
+	i.regexCache[pattern] = re
+	return nil
+}
+
+// state holds the per-record evaluation context: the field vector (1
+// NR, plus NF derived from len(fields)), and any user-defined variables.
+type state struct {
+	fields []string
+	nr     int
+	vars   map[string]value
+	kept   bool
+	out    []string
+}
+
+// value is either a float64 or a string; uninitialized variables read as
+// the zero value of whichever type the caller asks for, mirroring AWK's
+// untyped scalars.
+type value struct {
+	num   float64
+	str   string
+	isStr bool
+}
+
+func numVal(f float64) value { return value{num: f} }
+func strVal(s string) value  { return value{str: s, isStr: true} }
+
+func (v value) toNum() float64 {
+	if !v.isStr {
+		return v.num
+	}
+	f, _ := strconv.ParseFloat(strings.TrimSpace(v.str), 64)
+	return f
+}
+
+func (v value) toStr() string {
+	if v.isStr {
+		return v.str
+	}
+	return strconv.FormatFloat(v.num, 'f', -1, 64)
+}
+
+func (v value) truthy() bool {
+	if v.isStr {
+		return v.str != ""
+	}
+	return v.num != 0
+}
+
+// Run evaluates the program against one record. It returns the output
+// record (valid only when keep is true) and whether the record survives
+// to the output sink: keep is true only once a `print` statement runs,
+// and becomes permanently false if `next` runs first.
+func (i *Interp) Run(record []string, nr int) (out []string, keep bool, err error) {
+	st := &state{
+		fields: append([]string(nil), record...),
+		nr:     nr,
+		vars:   make(map[string]value),
+	}
+	err = i.execStmts(st, i.prog.Stmts)
+	if err == errNext {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return st.out, st.kept, nil
+}
+
+func (i *Interp) execStmts(st *state, stmts []Stmt) error {
+	for _, s := range stmts {
+		if err := i.execStmt(st, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (i *Interp) execStmt(st *state, s Stmt) error {
+	switch x := s.(type) {
+	case ExprStmt:
+		_, err := i.eval(st, x.X)
+		return err
+	case IfStmt:
+		cond, err := i.eval(st, x.Cond)
+		if err != nil {
+			return err
+		}
+		if cond.truthy() {
+			return i.execStmts(st, x.Then)
+		}
+		return i.execStmts(st, x.Else)
+	case NextStmt:
+		return errNext
+	case PrintStmt:
+		if len(x.Args) == 0 {
+			st.out = append([]string(nil), st.fields...)
+		} else {
+			row := make([]string, len(x.Args))
+			for idx, a := range x.Args {
+				v, err := i.eval(st, a)
+				if err != nil {
+					return err
+				}
+				row[idx] = v.toStr()
+			}
+			st.out = row
+		}
+		st.kept = true
+		return nil
+	default:
+		return fmt.Errorf("expr: unknown statement %T", s)
+	}
+}
+
+func (i *Interp) eval(st *state, e Expr) (value, error) {
+	switch x := e.(type) {
+	case NumberLit:
+		return numVal(x.Value), nil
+	case StringLit:
+		return strVal(x.Value), nil
+	case RegexLit:
+		return strVal(x.Pattern), nil
+	case Ident:
+		return i.evalIdent(st, x.Name), nil
+	case FieldRef:
+		idx, err := i.eval(st, x.Index)
+		if err != nil {
+			return value{}, err
+		}
+		return strVal(st.field(int(idx.toNum()))), nil
+	case Assign:
+		v, err := i.eval(st, x.Value)
+		if err != nil {
+			return value{}, err
+		}
+		return v, i.assign(st, x.Target, v)
+	case UnaryExpr:
+		return i.evalUnary(st, x)
+	case BinaryExpr:
+		return i.evalBinary(st, x)
+	case Call:
+		return i.evalCall(st, x)
+	default:
+		return value{}, fmt.Errorf("expr: unknown expression %T", e)
+	}
+}
+
+func (i *Interp) evalIdent(st *state, name string) value {
+	switch name {
+	case "NR":
+		return numVal(float64(st.nr))
+	case "NF":
+		return numVal(float64(len(st.fields)))
+	}
+	return st.vars[name]
+}
+
+func (i *Interp) assign(st *state, target Expr, v value) error {
+	switch t := target.(type) {
+	case Ident:
+		st.vars[t.Name] = v
+		return nil
+	case FieldRef:
+		idxVal, err := i.eval(st, t.Index)
+		if err != nil {
+			return err
+		}
+		idx := int(idxVal.toNum())
+		if idx == 0 {
+			st.fields = strings.Split(v.toStr(), ",")
+			return nil
+		}
+		for len(st.fields) < idx {
+			st.fields = append(st.fields, "")
+		}
+		st.fields[idx-1] = v.toStr()
+		return nil
+	default:
+		return fmt.Errorf("expr: invalid assignment target %T", target)
+	}
+}
+
+func (s *state) field(idx int) string {
+	if idx == 0 {
+		return strings.Join(s.fields, ",")
+	}
+	if idx < 1 || idx > len(s.fields) {
+		return ""
+	}
+	return s.fields[idx-1]
+}
+
+func (i *Interp) evalUnary(st *state, x UnaryExpr) (value, error) {
+	v, err := i.eval(st, x.X)
+	if err != nil {
+		return value{}, err
+	}
+	switch x.Op {
+	case tMinus:
+		return numVal(-v.toNum()), nil
+	case tNot:
+		if v.truthy() {
+			return numVal(0), nil
+		}
+		return numVal(1), nil
+	default:
+		return value{}, fmt.Errorf("expr: unknown unary operator")
+	}
+}
+
+func (i *Interp) evalBinary(st *state, x BinaryExpr) (value, error) {
+	if x.Op == tAnd || x.Op == tOr {
+		l, err := i.eval(st, x.L)
+		if err != nil {
+			return value{}, err
+		}
+		if x.Op == tAnd && !l.truthy() {
+			return numVal(0), nil
+		}
+		if x.Op == tOr && l.truthy() {
+			return numVal(1), nil
+		}
+		r, err := i.eval(st, x.R)
+		if err != nil {
+			return value{}, err
+		}
+		return boolVal(r.truthy()), nil
+	}
+
+	l, err := i.eval(st, x.L)
+	if err != nil {
+		return value{}, err
+	}
+	r, err := i.eval(st, x.R)
+	if err != nil {
+		return value{}, err
+	}
+
+	switch x.Op {
+	case tPlus:
+		return numVal(l.toNum() + r.toNum()), nil
+	case tMinus:
+		return numVal(l.toNum() - r.toNum()), nil
+	case tStar:
+		return numVal(l.toNum() * r.toNum()), nil
+	case tSlash:
+		return numVal(l.toNum() / r.toNum()), nil
+	case tPercent:
+		li, ri := int64(l.toNum()), int64(r.toNum())
+		if ri == 0 {
+			return value{}, fmt.Errorf("expr: modulo by zero")
+		}
+		return numVal(float64(li % ri)), nil
+	case tEq:
+		return boolVal(l.toStr() == r.toStr()), nil
+	case tNeq:
+		return boolVal(l.toStr() != r.toStr()), nil
+	case tLt:
+		return boolVal(l.toNum() < r.toNum()), nil
+	case tLte:
+		return boolVal(l.toNum() <= r.toNum()), nil
+	case tGt:
+		return boolVal(l.toNum() > r.toNum()), nil
+	case tGte:
+		return boolVal(l.toNum() >= r.toNum()), nil
+	case tMatch, tNotMatch:
+		re, err := i.regexFor(r)
+		if err != nil {
+			return value{}, err
+		}
+		matched := re.MatchString(l.toStr())
+		if x.Op == tNotMatch {
+			matched = !matched
+		}
+		return boolVal(matched), nil
+	default:
+		return value{}, fmt.Errorf("expr: unknown binary operator")
+	}
+}
+
+func (i *Interp) regexFor(v value) (*regexp.Regexp, error) {
+	pattern := v.toStr()
+	i.regexMu.Lock()
+	re, ok := i.regexCache[pattern]
+	i.regexMu.Unlock()
+	if ok {
+		return re, nil
+	}
+	if err := i.compileRegex(pattern); err != nil {
+		return nil, err
+	}
+	i.regexMu.Lock()
+	re = i.regexCache[pattern]
+	i.regexMu.Unlock()
+	return re, nil
+}
+
+func boolVal(b bool) value {
+	if b {
+		return numVal(1)
+	}
+	return numVal(0)
+}
+
+func (i *Interp) evalCall(st *state, c Call) (value, error) {
+	args := make([]value, len(c.Args))
+	for idx, a := range c.Args {
+		v, err := i.eval(st, a)
+		if err != nil {
+			return value{}, err
+		}
+		args[idx] = v
+	}
+
+	switch c.Name {
+	case "toupper":
+		return strVal(strings.ToUpper(arg(args, 0).toStr())), nil
+	case "tolower":
+		return strVal(strings.ToLower(arg(args, 0).toStr())), nil
+	case "length":
+		if len(args) == 0 {
+			return numVal(float64(len(st.fields))), nil
+		}
+		return numVal(float64(len([]rune(args[0].toStr())))), nil
+	case "substr":
+		s := []rune(arg(args, 0).toStr())
+		start := int(arg(args, 1).toNum())
+		if start < 1 {
+			start = 1
+		}
+		if start > len(s)+1 {
+			start = len(s) + 1
+		}
+		end := len(s) + 1
+		if len(args) >= 3 {
+			n := int(args[2].toNum())
+			if start-1+n < end {
+				end = start - 1 + n
+			}
+		}
+		if end < start {
+			end = start
+		}
+		return strVal(string(s[start-1 : end-1])), nil
+	case "split":
+		// This language has no array variables, so split returns only
+		// the resulting field count, matching AWK's return value but
+		// not AWK's array side effect.
+		sep := ","
+		if len(args) >= 2 {
+			sep = args[1].toStr()
+		}
+		parts := strings.Split(arg(args, 0).toStr(), sep)
+		return numVal(float64(len(parts))), nil
+	case "sprintf":
+		if len(args) == 0 {
+			return strVal(""), nil
+		}
+		rest := make([]interface{}, len(args)-1)
+		for idx, a := range args[1:] {
+			rest[idx] = a.toStr()
+		}
+		return strVal(fmt.Sprintf(args[0].toStr(), rest...)), nil
+	case "match":
+		re, err := i.regexFor(arg(args, 1))
+		if err != nil {
+			return value{}, err
+		}
+		loc := re.FindStringIndex(arg(args, 0).toStr())
+		if loc == nil {
+			return numVal(0), nil
+		}
+		return numVal(float64(loc[0] + 1)), nil
+	default:
+		return value{}, fmt.Errorf("expr: unknown function %q", c.Name)
+	}
+}
+
+func arg(args []value, idx int) value {
+	if idx >= len(args) {
+		return value{}
+	}
+	return args[idx]
+}
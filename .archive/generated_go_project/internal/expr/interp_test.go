@@ -0,0 +1,63 @@
+package expr
+
+import (
+	"reflect"
+	"testing"
+)
+
+func run(t *testing.T, script string, record []string, nr int) (out []string, keep bool) {
+	t.Helper()
+	prog, err := Parse(script)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", script, err)
+	}
+	interp, err := NewInterp(prog)
+	if err != nil {
+		t.Fatalf("NewInterp(%q): %v", script, err)
+	}
+	out, keep, err = interp.Run(record, nr)
+	if err != nil {
+		t.Fatalf("Run(%q): %v", script, err)
+	}
+	return out, keep
+}
+
+func TestRunToUpperAssignment(t *testing.T) {
+	out, keep := run(t, "$1 = toupper($1); print", []string{"alice", "30"}, 1)
+	if !keep {
+		t.Fatal("keep = false, want true")
+	}
+	want := []string{"ALICE", "30"}
+	if !reflect.DeepEqual(out, want) {
+		t.Errorf("out = %v, want %v", out, want)
+	}
+}
+
+func TestRunNextDropsRecord(t *testing.T) {
+	_, keep := run(t, `if ($2 ~ /^err/) next`, []string{"x", "error"}, 1)
+	if keep {
+		t.Error("keep = true, want false (next should drop the record)")
+	}
+}
+
+func TestRunIfElse(t *testing.T) {
+	out, keep := run(t, `if ($2 == "30") { $1 = "match" } else { $1 = "nomatch" }; print`,
+		[]string{"x", "30"}, 1)
+	if !keep || out[0] != "match" {
+		t.Errorf("out = %v keep = %v, want [match ...] true", out, keep)
+	}
+}
+
+func TestRunNRBuiltin(t *testing.T) {
+	out, _ := run(t, "$1 = NR; print", []string{"placeholder"}, 7)
+	if out[0] != "7" {
+		t.Errorf("$1 = %q, want 7", out[0])
+	}
+}
+
+func TestRunArithmetic(t *testing.T) {
+	out, _ := run(t, "$1 = $1 + $2 * 2; print", []string{"3", "4"}, 1)
+	if out[0] != "11" {
+		t.Errorf("$1 = %q, want 11", out[0])
+	}
+}
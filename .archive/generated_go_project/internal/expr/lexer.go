@@ -0,0 +1,259 @@
+// Package expr implements a small AWK-inspired expression language for
+// per-record CSV transformations: $N/$0 field references, NR/NF builtins,
+// arithmetic/comparison/regex-match operators, if/else, next, print, and
+// a handful of string builtins (toupper, tolower, substr, split, sprintf,
+// length, match).
+package expr
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tEOF tokenKind = iota
+	tNumber
+	tString
+	tIdent
+	tDollar
+	tRegex
+
+	tPlus
+	tMinus
+	tStar
+	tSlash
+	tPercent
+	tAssign
+	tEq
+	tNeq
+	tLt
+	tLte
+	tGt
+	tGte
+	tMatch
+	tNotMatch
+	tAnd
+	tOr
+	tNot
+	tLParen
+	tRParen
+	tLBrace
+	tRBrace
+	tSemi
+	tComma
+
+	tIf
+	tElse
+	tNext
+	tPrint
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	num  float64
+}
+
+// lexer turns program source into a token stream. Regex literals
+// (/pattern/) are only valid where an operand is expected, so the lexer
+// tracks whether the previous token could end an expression to decide
+// whether '/' starts a division operator or a regex literal.
+type lexer struct {
+	src        []rune
+	pos        int
+	lastWasVal bool
+}
+
+func newLexer(src string) *lexer {
+	return &lexer{src: []rune(src)}
+}
+
+func (l *lexer) peekRune() rune {
+	if l.pos >= len(l.src) {
+		return 0
+	}
+	return l.src[l.pos]
+}
+
+func (l *lexer) next() (token, error) {
+	l.skipSpace()
+	if l.pos >= len(l.src) {
+		return token{kind: tEOF}, nil
+	}
+	c := l.src[l.pos]
+
+	switch {
+	case c == '$':
+		l.pos++
+		l.lastWasVal = false
+		return token{kind: tDollar}, nil
+	case c >= '0' && c <= '9':
+		return l.lexNumber(), nil
+	case c == '"':
+		return l.lexString()
+	case isIdentStart(c):
+		return l.lexIdent(), nil
+	case c == '/' && !l.lastWasVal:
+		return l.lexRegex()
+	}
+
+	two := ""
+	if l.pos+1 < len(l.src) {
+		two = string(l.src[l.pos : l.pos+2])
+	}
+	advance := func(n int, k tokenKind, val bool) (token, error) {
+		l.pos += n
+		l.lastWasVal = val
+		return token{kind: k}, nil
+	}
+
+	switch two {
+	case "==":
+		return advance(2, tEq, false)
+	case "!=":
+		return advance(2, tNeq, false)
+	case "<=":
+		return advance(2, tLte, false)
+	case ">=":
+		return advance(2, tGte, false)
+	case "!~":
+		return advance(2, tNotMatch, false)
+	case "&&":
+		return advance(2, tAnd, false)
+	case "||":
+		return advance(2, tOr, false)
+	}
+
+	switch c {
+	case '+':
+		return advance(1, tPlus, false)
+	case '-':
+		return advance(1, tMinus, false)
+	case '*':
+		return advance(1, tStar, false)
+	case '/':
+		return advance(1, tSlash, false)
+	case '%':
+		return advance(1, tPercent, false)
+	case '=':
+		return advance(1, tAssign, false)
+	case '<':
+		return advance(1, tLt, false)
+	case '>':
+		return advance(1, tGt, false)
+	case '~':
+		return advance(1, tMatch, false)
+	case '!':
+		return advance(1, tNot, false)
+	case '(':
+		return advance(1, tLParen, false)
+	case ')':
+		return advance(1, tRParen, true)
+	case '{':
+		return advance(1, tLBrace, false)
+	case '}':
+		return advance(1, tRBrace, false)
+	case ';':
+		return advance(1, tSemi, false)
+	case ',':
+		return advance(1, tComma, false)
+	}
+
+	return token{}, fmt.Errorf("expr: unexpected character %q at offset %d", c, l.pos)
+}
+
+func (l *lexer) skipSpace() {
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' {
+			l.pos++
+			continue
+		}
+		break
+	}
+}
+
+func (l *lexer) lexNumber() token {
+	start := l.pos
+	for l.pos < len(l.src) && (isDigit(l.src[l.pos]) || l.src[l.pos] == '.') {
+		l.pos++
+	}
+	text := string(l.src[start:l.pos])
+	var f float64
+	fmt.Sscanf(text, "%g", &f)
+	l.lastWasVal = true
+	return token{kind: tNumber, text: text, num: f}
+}
+
+func (l *lexer) lexString() (token, error) {
+	l.pos++ // opening quote
+	var sb strings.Builder
+	for l.pos < len(l.src) && l.src[l.pos] != '"' {
+		c := l.src[l.pos]
+		if c == '\\' && l.pos+1 < len(l.src) {
+			l.pos++
+			switch l.src[l.pos] {
+			case 'n':
+				sb.WriteRune('\n')
+			case 't':
+				sb.WriteRune('\t')
+			default:
+				sb.WriteRune(l.src[l.pos])
+			}
+		} else {
+			sb.WriteRune(c)
+		}
+		l.pos++
+	}
+	if l.pos >= len(l.src) {
+		return token{}, fmt.Errorf("expr: unterminated string literal")
+	}
+	l.pos++ // closing quote
+	l.lastWasVal = true
+	return token{kind: tString, text: sb.String()}, nil
+}
+
+func (l *lexer) lexRegex() (token, error) {
+	l.pos++ // opening slash
+	start := l.pos
+	for l.pos < len(l.src) && l.src[l.pos] != '/' {
+		if l.src[l.pos] == '\\' {
+			l.pos++
+		}
+		l.pos++
+	}
+	if l.pos >= len(l.src) {
+		return token{}, fmt.Errorf("expr: unterminated regex literal")
+	}
+	text := string(l.src[start:l.pos])
+	l.pos++ // closing slash
+	l.lastWasVal = true
+	return token{kind: tRegex, text: text}, nil
+}
+
+var keywords = map[string]tokenKind{
+	"if":    tIf,
+	"else":  tElse,
+	"next":  tNext,
+	"print": tPrint,
+}
+
+func (l *lexer) lexIdent() token {
+	start := l.pos
+	for l.pos < len(l.src) && isIdentPart(l.src[l.pos]) {
+		l.pos++
+	}
+	text := string(l.src[start:l.pos])
+	if kind, ok := keywords[text]; ok {
+		l.lastWasVal = false
+		return token{kind: kind, text: text}
+	}
+	l.lastWasVal = true
+	return token{kind: tIdent, text: text}
+}
+
+func isDigit(c rune) bool      { return c >= '0' && c <= '9' }
+func isIdentStart(c rune) bool { return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') }
+func isIdentPart(c rune) bool  { return isIdentStart(c) || isDigit(c) }
@@ -0,0 +1,326 @@
+package expr
+
+import "fmt"
+
+// Parse compiles an --expr script into a Program. Regexes are compiled at
+// interpreter construction time (see NewInterp), not here; the parser only
+// builds the AST.
+func Parse(src string) (*Program, error) {
+	p := &parser{lex: newLexer(src)}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	stmts, err := p.parseStmts(tEOF)
+	if err != nil {
+		return nil, err
+	}
+	return &Program{Stmts: stmts}, nil
+}
+
+type parser struct {
+	lex *lexer
+	tok token
+}
+
+func (p *parser) advance() error {
+	t, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = t
+	return nil
+}
+
+func (p *parser) expect(k tokenKind, what string) error {
+	if p.tok.kind != k {
+		return fmt.Errorf("expr: expected %s", what)
+	}
+	return p.advance()
+}
+
+func (p *parser) parseStmts(end tokenKind) ([]Stmt, error) {
+	var stmts []Stmt
+	for p.tok.kind != end && p.tok.kind != tRBrace {
+		for p.tok.kind == tSemi {
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+		}
+		if p.tok.kind == end || p.tok.kind == tRBrace {
+			break
+		}
+		s, err := p.parseStmt()
+		if err != nil {
+			return nil, err
+		}
+		stmts = append(stmts, s)
+		for p.tok.kind == tSemi {
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return stmts, nil
+}
+
+func (p *parser) parseBlock() ([]Stmt, error) {
+	if p.tok.kind == tLBrace {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		stmts, err := p.parseStmts(tRBrace)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tRBrace, "'}'"); err != nil {
+			return nil, err
+		}
+		return stmts, nil
+	}
+	s, err := p.parseStmt()
+	if err != nil {
+		return nil, err
+	}
+	return []Stmt{s}, nil
+}
+
+func (p *parser) parseStmt() (Stmt, error) {
+	switch p.tok.kind {
+	case tIf:
+		return p.parseIf()
+	case tNext:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		return NextStmt{}, nil
+	case tPrint:
+		return p.parsePrint()
+	default:
+		e, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		return ExprStmt{X: e}, nil
+	}
+}
+
+func (p *parser) parseIf() (Stmt, error) {
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if err := p.expect(tLParen, "'('"); err != nil {
+		return nil, err
+	}
+	cond, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(tRParen, "')'"); err != nil {
+		return nil, err
+	}
+	then, err := p.parseBlock()
+	if err != nil {
+		return nil, err
+	}
+	var els []Stmt
+	if p.tok.kind == tElse {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		els, err = p.parseBlock()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return IfStmt{Cond: cond, Then: then, Else: els}, nil
+}
+
+func (p *parser) parsePrint() (Stmt, error) {
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind == tSemi || p.tok.kind == tEOF || p.tok.kind == tRBrace {
+		return PrintStmt{}, nil
+	}
+	var args []Expr
+	for {
+		e, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, e)
+		if p.tok.kind != tComma {
+			break
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+	return PrintStmt{Args: args}, nil
+}
+
+// parseExpr handles assignment, the lowest-precedence operator and the
+// only right-associative one.
+func (p *parser) parseExpr() (Expr, error) {
+	lhs, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind == tAssign {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		rhs, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		return Assign{Target: lhs, Value: rhs}, nil
+	}
+	return lhs, nil
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	return p.parseBinaryLevel([]tokenKind{tOr}, p.parseAnd)
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	return p.parseBinaryLevel([]tokenKind{tAnd}, p.parseMatch)
+}
+
+func (p *parser) parseMatch() (Expr, error) {
+	return p.parseBinaryLevel([]tokenKind{tMatch, tNotMatch}, p.parseComparison)
+}
+
+func (p *parser) parseComparison() (Expr, error) {
+	return p.parseBinaryLevel([]tokenKind{tEq, tNeq, tLt, tLte, tGt, tGte}, p.parseAdditive)
+}
+
+func (p *parser) parseAdditive() (Expr, error) {
+	return p.parseBinaryLevel([]tokenKind{tPlus, tMinus}, p.parseMultiplicative)
+}
+
+func (p *parser) parseMultiplicative() (Expr, error) {
+	return p.parseBinaryLevel([]tokenKind{tStar, tSlash, tPercent}, p.parseUnary)
+}
+
+func (p *parser) parseBinaryLevel(ops []tokenKind, next func() (Expr, error)) (Expr, error) {
+	lhs, err := next()
+	if err != nil {
+		return nil, err
+	}
+	for containsKind(ops, p.tok.kind) {
+		op := p.tok.kind
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		rhs, err := next()
+		if err != nil {
+			return nil, err
+		}
+		lhs = BinaryExpr{Op: op, L: lhs, R: rhs}
+	}
+	return lhs, nil
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	if p.tok.kind == tNot || p.tok.kind == tMinus {
+		op := p.tok.kind
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return UnaryExpr{Op: op, X: x}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Expr, error) {
+	switch p.tok.kind {
+	case tNumber:
+		v := p.tok.num
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		return NumberLit{Value: v}, nil
+	case tString:
+		v := p.tok.text
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		return StringLit{Value: v}, nil
+	case tRegex:
+		v := p.tok.text
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		return RegexLit{Pattern: v}, nil
+	case tDollar:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		idx, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		return FieldRef{Index: idx}, nil
+	case tIdent:
+		name := p.tok.text
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if p.tok.kind == tLParen {
+			return p.parseCall(name)
+		}
+		return Ident{Name: name}, nil
+	case tLParen:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		e, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tRParen, "')'"); err != nil {
+			return nil, err
+		}
+		return e, nil
+	}
+	return nil, fmt.Errorf("expr: unexpected token in expression")
+}
+
+func (p *parser) parseCall(name string) (Expr, error) {
+	if err := p.advance(); err != nil { // consume '('
+		return nil, err
+	}
+	var args []Expr
+	for p.tok.kind != tRParen {
+		e, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, e)
+		if p.tok.kind == tComma {
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		break
+	}
+	if err := p.expect(tRParen, "')'"); err != nil {
+		return nil, err
+	}
+	return Call{Name: name, Args: args}, nil
+}
+
+func containsKind(ks []tokenKind, k tokenKind) bool {
+	for _, x := range ks {
+		if x == k {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,185 @@
+// Package pipeline implements a per-column transformation pipeline for
+// struct-tagged CSV records, inspired by gocsv's tag-driven marshaling but
+// adding a fluent builder for chaining field-level transforms such as
+// upper/lower-casing, trimming, regexp substitution, date reformatting and
+// numeric rounding.
+package pipeline
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Transform converts a single field value, returning an error if the value
+// cannot be transformed (e.g. a ParseDate transform applied to a
+// non-matching string).
+type Transform func(string) (string, error)
+
+// Pipeline holds an ordered set of per-column transforms. Transforms for a
+// given column run in the order they were added.
+type Pipeline struct {
+	order   []string
+	columns map[string][]Transform
+}
+
+// New creates an empty Pipeline.
+func New() *Pipeline {
+	return &Pipeline{columns: make(map[string][]Transform)}
+}
+
+// Column starts (or resumes) building the transform chain for the named
+// column and returns a ColumnBuilder for fluent chaining.
+func (p *Pipeline) Column(name string) *ColumnBuilder {
+	if _, ok := p.columns[name]; !ok {
+		p.order = append(p.order, name)
+	}
+	return &ColumnBuilder{pipeline: p, name: name}
+}
+
+func (p *Pipeline) add(name string, t Transform) {
+	if _, ok := p.columns[name]; !ok {
+		p.order = append(p.order, name)
+	}
+	p.columns[name] = append(p.columns[name], t)
+}
+
+// Apply runs every registered transform against row, which maps column name
+// to field value, and returns the transformed row. Columns with no
+// registered transforms pass through unchanged.
+func (p *Pipeline) Apply(row map[string]string) (map[string]string, error) {
+	out := make(map[string]string, len(row))
+	for k, v := range row {
+		out[k] = v
+	}
+	for _, name := range p.order {
+		val, ok := out[name]
+		if !ok {
+			continue
+		}
+		for _, t := range p.columns[name] {
+			transformed, err := t(val)
+			if err != nil {
+				return nil, fmt.Errorf("column %q: %w", name, err)
+			}
+			val = transformed
+		}
+		out[name] = val
+	}
+	return out, nil
+}
+
+// ColumnBuilder accumulates transforms for one column before returning
+// control to the Pipeline so callers can chain straight into the next
+// column, e.g. p.Column("name").Upper().Trim().Column("amount").Numeric().Round(2).
+type ColumnBuilder struct {
+	pipeline *Pipeline
+	name     string
+}
+
+// Upper uppercases the field value.
+func (c *ColumnBuilder) Upper() *ColumnBuilder {
+	c.pipeline.add(c.name, Upper)
+	return c
+}
+
+// Lower lowercases the field value.
+func (c *ColumnBuilder) Lower() *ColumnBuilder {
+	c.pipeline.add(c.name, Lower)
+	return c
+}
+
+// Trim strips leading and trailing whitespace from the field value.
+func (c *ColumnBuilder) Trim() *ColumnBuilder {
+	c.pipeline.add(c.name, Trim)
+	return c
+}
+
+// Regexp replaces every match of pattern in the field value with repl.
+// It panics at build time if pattern fails to compile, mirroring how
+// regexp.MustCompile is used elsewhere for pipeline-time constants.
+func (c *ColumnBuilder) Regexp(pattern, repl string) *ColumnBuilder {
+	c.pipeline.add(c.name, RegexpTransform(pattern, repl))
+	return c
+}
+
+// ParseDate reparses the field value using layout and rewrites it as
+// RFC3339.
+func (c *ColumnBuilder) ParseDate(layout string) *ColumnBuilder {
+	c.pipeline.add(c.name, ParseDate(layout))
+	return c
+}
+
+// Numeric returns a NumericBuilder for numeric-only transforms on this
+// column, matching the requested `Numeric.Round(n)` spelling.
+func (c *ColumnBuilder) Numeric() *NumericBuilder {
+	return &NumericBuilder{ColumnBuilder: c}
+}
+
+// Column switches the chain to another column, returning its builder.
+func (c *ColumnBuilder) Column(name string) *ColumnBuilder {
+	return c.pipeline.Column(name)
+}
+
+// Done returns the underlying Pipeline, useful at the end of a chain.
+func (c *ColumnBuilder) Done() *Pipeline {
+	return c.pipeline
+}
+
+// NumericBuilder namespaces numeric-only transforms under ColumnBuilder.
+type NumericBuilder struct {
+	*ColumnBuilder
+}
+
+// Round rounds the field value, parsed as a float64, to n decimal places.
+func (n *NumericBuilder) Round(digits int) *ColumnBuilder {
+	n.pipeline.add(n.name, Round(digits))
+	return n.ColumnBuilder
+}
+
+// Upper is the Transform form of strings.ToUpper.
+func Upper(s string) (string, error) { return strings.ToUpper(s), nil }
+
+// Lower is the Transform form of strings.ToLower.
+func Lower(s string) (string, error) { return strings.ToLower(s), nil }
+
+// Trim is the Transform form of strings.TrimSpace.
+func Trim(s string) (string, error) { return strings.TrimSpace(s), nil }
+
+// RegexpTransform builds a Transform that replaces every match of pattern
+// with repl (which may reference capture groups as $1, $2, ...).
+func RegexpTransform(pattern, repl string) Transform {
+	re := regexp.MustCompile(pattern)
+	return func(s string) (string, error) {
+		return re.ReplaceAllString(s, repl), nil
+	}
+}
+
+// ParseDate builds a Transform that parses s with layout and rewrites it
+// as RFC3339.
+func ParseDate(layout string) Transform {
+	return func(s string) (string, error) {
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			return "", fmt.Errorf("parse date %q with layout %q: %w", s, layout, err)
+		}
+		return t.Format(time.RFC3339), nil
+	}
+}
+
+// Round builds a Transform that parses s as a float64 and re-renders it
+// rounded to digits decimal places.
+func Round(digits int) Transform {
+	factor := math.Pow(10, float64(digits))
+	return func(s string) (string, error) {
+		f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil {
+			return "", fmt.Errorf("round %q: %w", s, err)
+		}
+		rounded := math.Round(f*factor) / factor
+		return strconv.FormatFloat(rounded, 'f', digits, 64), nil
+	}
+}
@@ -0,0 +1,108 @@
+package pipeline
+
+import "testing"
+
+func TestPipelineApply(t *testing.T) {
+	p := New()
+	p.Column("name").Upper().Trim()
+	p.Column("amount").Numeric().Round(2)
+
+	out, err := p.Apply(map[string]string{"name": " alice ", "amount": "1.239"})
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if out["name"] != "ALICE" {
+		t.Errorf("name = %q, want ALICE", out["name"])
+	}
+	if out["amount"] != "1.24" {
+		t.Errorf("amount = %q, want 1.24", out["amount"])
+	}
+}
+
+func TestPipelineApplyPassesThroughUnregisteredColumns(t *testing.T) {
+	p := New()
+	p.Column("name").Upper()
+
+	out, err := p.Apply(map[string]string{"name": "bob", "id": "42"})
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if out["id"] != "42" {
+		t.Errorf("id = %q, want unchanged 42", out["id"])
+	}
+}
+
+func TestPipelineApplyPropagatesTransformError(t *testing.T) {
+	p := New()
+	p.Column("amount").Numeric().Round(2)
+
+	if _, err := p.Apply(map[string]string{"amount": "not-a-number"}); err == nil {
+		t.Error("Apply: expected error for non-numeric amount, got nil")
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	out, err := ParseDate("2006-01-02")("2024-03-05")
+	if err != nil {
+		t.Fatalf("ParseDate: %v", err)
+	}
+	if out != "2024-03-05T00:00:00Z" {
+		t.Errorf("ParseDate = %q, want 2024-03-05T00:00:00Z", out)
+	}
+}
+
+func TestRegexpTransform(t *testing.T) {
+	out, err := RegexpTransform(`\s+`, "_")("a  b   c")
+	if err != nil {
+		t.Fatalf("RegexpTransform: %v", err)
+	}
+	if out != "a_b_c" {
+		t.Errorf("RegexpTransform = %q, want a_b_c", out)
+	}
+}
+
+type testRow struct {
+	Name   string  `csv:"name"`
+	Amount float64 `csv:"amount"`
+	Active bool    `csv:"active"`
+}
+
+func TestDecodeEncodeRowRoundTrip(t *testing.T) {
+	header := []string{"name", "amount", "active"}
+	record := []string{"alice", "12.5", "true"}
+
+	row, err := DecodeRow[testRow](header, record)
+	if err != nil {
+		t.Fatalf("DecodeRow: %v", err)
+	}
+	if row.Name != "alice" || row.Amount != 12.5 || !row.Active {
+		t.Fatalf("DecodeRow = %+v, want {alice 12.5 true}", row)
+	}
+
+	encoded, err := EncodeRow(header, row)
+	if err != nil {
+		t.Fatalf("EncodeRow: %v", err)
+	}
+	want := []string{"alice", "12.5", "true"}
+	for i, v := range want {
+		if encoded[i] != v {
+			t.Errorf("encoded[%d] = %q, want %q", i, encoded[i], v)
+		}
+	}
+}
+
+func TestHeaderReadsCSVTags(t *testing.T) {
+	header, err := Header[testRow]()
+	if err != nil {
+		t.Fatalf("Header: %v", err)
+	}
+	want := []string{"name", "amount", "active"}
+	if len(header) != len(want) {
+		t.Fatalf("Header = %v, want %v", header, want)
+	}
+	for i, v := range want {
+		if header[i] != v {
+			t.Errorf("header[%d] = %q, want %q", i, header[i], v)
+		}
+	}
+}
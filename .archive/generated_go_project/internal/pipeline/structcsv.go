@@ -0,0 +1,131 @@
+package pipeline
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// tagFields returns, for struct type t, the list of CSV column names in
+// field order and the corresponding struct field index, reading the
+// `csv:"column_name"` tag. Fields without a csv tag are skipped.
+func tagFields(t reflect.Type) ([]string, []int, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, nil, fmt.Errorf("pipeline: %s is not a struct", t)
+	}
+	var names []string
+	var idx []int
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("csv")
+		if tag == "" || tag == "-" {
+			continue
+		}
+		names = append(names, tag)
+		idx = append(idx, i)
+	}
+	return names, idx, nil
+}
+
+// Header returns the CSV column names for struct type T in declaration
+// order, as read from `csv` struct tags.
+func Header[T any]() ([]string, error) {
+	var zero T
+	names, _, err := tagFields(reflect.TypeOf(zero))
+	return names, err
+}
+
+// DecodeRow populates a new T from a CSV record, using header to map
+// record positions to column names and `csv` struct tags to map column
+// names to fields. Supported field kinds are string, int family, float
+// family and bool.
+func DecodeRow[T any](header, record []string) (T, error) {
+	var out T
+	v := reflect.ValueOf(&out).Elem()
+	t := v.Type()
+	names, idx, err := tagFields(t)
+	if err != nil {
+		return out, err
+	}
+	pos := make(map[string]int, len(header))
+	for i, h := range header {
+		pos[h] = i
+	}
+	for i, name := range names {
+		col, ok := pos[name]
+		if !ok || col >= len(record) {
+			continue
+		}
+		if err := setField(v.Field(idx[i]), record[col]); err != nil {
+			return out, fmt.Errorf("column %q: %w", name, err)
+		}
+	}
+	return out, nil
+}
+
+// EncodeRow renders a T back into a CSV record ordered to match header.
+func EncodeRow[T any](header []string, row T) ([]string, error) {
+	v := reflect.ValueOf(row)
+	t := v.Type()
+	names, idx, err := tagFields(t)
+	if err != nil {
+		return nil, err
+	}
+	fieldByName := make(map[string]int, len(names))
+	for i, name := range names {
+		fieldByName[name] = idx[i]
+	}
+	record := make([]string, len(header))
+	for i, name := range header {
+		fi, ok := fieldByName[name]
+		if !ok {
+			continue
+		}
+		record[i] = formatField(v.Field(fi))
+	}
+	return record, nil
+}
+
+func setField(f reflect.Value, s string) error {
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+		if err != nil {
+			return err
+		}
+		f.SetInt(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil {
+			return err
+		}
+		f.SetFloat(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(s))
+		if err != nil {
+			return err
+		}
+		f.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported field kind %s", f.Kind())
+	}
+	return nil
+}
+
+func formatField(f reflect.Value) string {
+	switch f.Kind() {
+	case reflect.String:
+		return f.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(f.Int(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(f.Float(), 'f', -1, 64)
+	case reflect.Bool:
+		return strconv.FormatBool(f.Bool())
+	default:
+		return fmt.Sprintf("%v", f.Interface())
+	}
+}
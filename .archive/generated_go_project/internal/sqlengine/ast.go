@@ -0,0 +1,98 @@
+package sqlengine
+
+import "strings"
+
+// Query is a parsed --sql statement.
+type Query struct {
+	Select  []SelectItem
+	From    string
+	Where   Expr // nil means no WHERE clause
+	GroupBy []string
+	OrderBy []OrderItem
+	Limit   int // -1 means no LIMIT
+}
+
+// SelectItem is one projected column: an expression and its output name.
+type SelectItem struct {
+	Expr  Expr
+	Alias string
+	Star  bool // SELECT * expands to every input column at execution time
+}
+
+// OrderItem is one ORDER BY key.
+type OrderItem struct {
+	Expr Expr
+	Desc bool
+}
+
+// IsAggregate reports whether the query's SELECT list uses an aggregate
+// function (sum/avg/min/max/count), which forces whole-table evaluation
+// even without an explicit GROUP BY (the implicit single group case).
+func (q *Query) IsAggregate() bool {
+	for _, item := range q.Select {
+		if exprUsesAggregate(item.Expr) {
+			return true
+		}
+	}
+	return false
+}
+
+// RequiresMaterialization reports whether the query needs the whole
+// table in memory (GROUP BY, ORDER BY, or a bare aggregate) rather than
+// the row-at-a-time streaming path.
+func (q *Query) RequiresMaterialization() bool {
+	return len(q.GroupBy) > 0 || len(q.OrderBy) > 0 || q.IsAggregate()
+}
+
+func exprUsesAggregate(e Expr) bool {
+	switch x := e.(type) {
+	case *FuncCall:
+		if isAggregateFunc(x.Name) {
+			return true
+		}
+		for _, a := range x.Args {
+			if exprUsesAggregate(a) {
+				return true
+			}
+		}
+	case *BinaryExpr:
+		return exprUsesAggregate(x.L) || exprUsesAggregate(x.R)
+	case *UnaryExpr:
+		return exprUsesAggregate(x.X)
+	}
+	return false
+}
+
+func isAggregateFunc(name string) bool {
+	switch strings.ToLower(name) {
+	case "sum", "avg", "min", "max", "count":
+		return true
+	}
+	return false
+}
+
+// Expr is a scalar (or aggregate-call) expression node.
+type Expr interface{ expr() }
+
+type NumberLit struct{ Value float64 }
+type StringLit struct{ Value string }
+type ColumnRef struct{ Name string }
+type FuncCall struct {
+	Name string
+	Args []Expr
+}
+type BinaryExpr struct {
+	Op   tokenKind
+	L, R Expr
+}
+type UnaryExpr struct {
+	Op tokenKind
+	X  Expr
+}
+
+func (*NumberLit) expr()  {}
+func (*StringLit) expr()  {}
+func (*ColumnRef) expr()  {}
+func (*FuncCall) expr()   {}
+func (*BinaryExpr) expr() {}
+func (*UnaryExpr) expr()  {}
@@ -0,0 +1,460 @@
+package sqlengine
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"generated_go_project/internal/tableio"
+)
+
+// Execute runs query against source, writing results to sink. Queries
+// without GROUP BY/ORDER BY/aggregates stream row by row so inputs
+// larger than RAM still work; everything else needs the whole table (or
+// whole group) in memory to compute sort order or aggregate values.
+func Execute(source tableio.Source, query *Query, sink tableio.Sink) (int, error) {
+	header := source.Header()
+	colIndex := make(map[string]int, len(header))
+	for i, h := range header {
+		colIndex[h] = i
+	}
+	ex := &executor{header: header, colIndex: colIndex}
+
+	outHeader, err := ex.outputHeader(query)
+	if err != nil {
+		return 0, err
+	}
+	if err := sink.WriteHeader(outHeader); err != nil {
+		return 0, err
+	}
+
+	if query.RequiresMaterialization() {
+		return ex.executeMaterialized(source, query, sink)
+	}
+	return ex.executeStreaming(source, query, sink)
+}
+
+type executor struct {
+	header   []string
+	colIndex map[string]int
+
+	// colTypes holds each column's sniffed type (see types.go),
+	// populated from the first row of a materialized query so ORDER BY
+	// can compare dates chronologically instead of lexicographically.
+	// It stays nil for the streaming path, which has no ORDER BY to
+	// serve.
+	colTypes map[string]colType
+
+	// regexCache holds compiled ~/!~ patterns keyed by source text, so a
+	// WHERE clause evaluated once per row (executeStreaming) or once per
+	// group row doesn't recompile the same regexp every time. An
+	// executor is only ever driven by one goroutine per Execute call, so
+	// this needs no locking (compare internal/expr's Interp, which adds
+	// a mutex because one Interp is shared across worker goroutines).
+	regexCache map[string]*regexp.Regexp
+}
+
+// sniffColumnTypes classifies every column using sample, typically the
+// first row of a materialized query's filtered result set.
+func (ex *executor) sniffColumnTypes(sample []string) {
+	ex.colTypes = make(map[string]colType, len(ex.header))
+	for i, col := range ex.header {
+		if i >= len(sample) {
+			continue
+		}
+		ex.colTypes[col] = sniffColumnType(sample[i])
+	}
+}
+
+func (ex *executor) outputHeader(query *Query) ([]string, error) {
+	var out []string
+	for _, item := range query.Select {
+		if item.Star {
+			out = append(out, ex.header...)
+			continue
+		}
+		out = append(out, item.Alias)
+	}
+	return out, nil
+}
+
+func (ex *executor) executeStreaming(source tableio.Source, query *Query, sink tableio.Sink) (int, error) {
+	count := 0
+	for {
+		row, err := source.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return count, err
+		}
+		if query.Where != nil {
+			v, err := ex.evalRows(query.Where, [][]string{row})
+			if err != nil {
+				return count, err
+			}
+			if !v.truthy() {
+				continue
+			}
+		}
+		outRow, err := ex.projectRows(query.Select, [][]string{row})
+		if err != nil {
+			return count, err
+		}
+		if err := sink.Write(outRow); err != nil {
+			return count, err
+		}
+		count++
+		if query.Limit >= 0 && count >= query.Limit {
+			break
+		}
+	}
+	return count, nil
+}
+
+func (ex *executor) executeMaterialized(source tableio.Source, query *Query, sink tableio.Sink) (int, error) {
+	var all [][]string
+	for {
+		row, err := source.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+		if query.Where != nil {
+			v, err := ex.evalRows(query.Where, [][]string{row})
+			if err != nil {
+				return 0, err
+			}
+			if !v.truthy() {
+				continue
+			}
+		}
+		all = append(all, row)
+	}
+
+	if len(query.OrderBy) > 0 && len(all) > 0 {
+		ex.sniffColumnTypes(all[0])
+	}
+
+	var groups [][][]string
+	if len(query.GroupBy) > 0 {
+		groups = ex.groupRows(all, query.GroupBy)
+	} else {
+		// No explicit GROUP BY: an aggregate SELECT treats the whole
+		// table as one group; a plain ORDER BY treats each row as its
+		// own one-row group.
+		if query.IsAggregate() {
+			groups = [][][]string{all}
+		} else {
+			for _, r := range all {
+				groups = append(groups, [][]string{r})
+			}
+		}
+	}
+
+	type outRow struct {
+		row       []string
+		orderVals []value
+	}
+	results := make([]outRow, 0, len(groups))
+	for _, g := range groups {
+		row, err := ex.projectRows(query.Select, g)
+		if err != nil {
+			return 0, err
+		}
+		var orderVals []value
+		for _, o := range query.OrderBy {
+			v, err := ex.evalRows(o.Expr, g)
+			if err != nil {
+				return 0, err
+			}
+			orderVals = append(orderVals, v)
+		}
+		results = append(results, outRow{row: row, orderVals: orderVals})
+	}
+
+	if len(query.OrderBy) > 0 {
+		sort.SliceStable(results, func(i, j int) bool {
+			for k, o := range query.OrderBy {
+				a, b := results[i].orderVals[k], results[j].orderVals[k]
+				cmp := compareValues(a, b)
+				if cmp == 0 {
+					continue
+				}
+				if o.Desc {
+					return cmp > 0
+				}
+				return cmp < 0
+			}
+			return false
+		})
+	}
+
+	count := 0
+	for _, r := range results {
+		if query.Limit >= 0 && count >= query.Limit {
+			break
+		}
+		if err := sink.Write(r.row); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// compareValues orders a and b chronologically when both were sniffed as
+// dates (see types.go), numerically when both look like numbers (this
+// covers the common case of a ColumnRef pulling a numeric column out of
+// the source, which arrives as a string value), and falls back to a
+// string comparison otherwise.
+func compareValues(a, b value) int {
+	if a.isDate && b.isDate {
+		switch {
+		case a.date.Before(b.date):
+			return -1
+		case a.date.After(b.date):
+			return 1
+		default:
+			return 0
+		}
+	}
+	an, aok := a.asNumber()
+	bn, bok := b.asNumber()
+	if aok && bok {
+		switch {
+		case an < bn:
+			return -1
+		case an > bn:
+			return 1
+		default:
+			return 0
+		}
+	}
+	return strings.Compare(a.toStr(), b.toStr())
+}
+
+// groupRows partitions rows by the values of the GROUP BY columns,
+// preserving first-seen group order.
+func (ex *executor) groupRows(rows [][]string, groupBy []string) [][][]string {
+	index := make(map[string]int)
+	var groups [][][]string
+	for _, row := range rows {
+		var keyParts []string
+		for _, col := range groupBy {
+			keyParts = append(keyParts, ex.cell(row, col))
+		}
+		key := strings.Join(keyParts, "\x1f")
+		if i, ok := index[key]; ok {
+			groups[i] = append(groups[i], row)
+			continue
+		}
+		index[key] = len(groups)
+		groups = append(groups, [][]string{row})
+	}
+	return groups
+}
+
+// regexFor compiles pattern on first use and reuses it for every later
+// row that matches against the same WHERE-clause literal.
+func (ex *executor) regexFor(pattern string) (*regexp.Regexp, error) {
+	if re, ok := ex.regexCache[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("sqlengine: bad regex %q: %w", pattern, err)
+	}
+	if ex.regexCache == nil {
+		ex.regexCache = make(map[string]*regexp.Regexp)
+	}
+	ex.regexCache[pattern] = re
+	return re, nil
+}
+
+func (ex *executor) cell(row []string, col string) string {
+	i, ok := ex.colIndex[col]
+	if !ok || i >= len(row) {
+		return ""
+	}
+	return row[i]
+}
+
+// projectRows renders query.Select against the rows of one group (a
+// single row for ungrouped queries).
+func (ex *executor) projectRows(items []SelectItem, rows [][]string) ([]string, error) {
+	var out []string
+	for _, item := range items {
+		if item.Star {
+			if len(rows) == 0 {
+				continue
+			}
+			out = append(out, rows[0]...)
+			continue
+		}
+		v, err := ex.evalRows(item.Expr, rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v.toStr())
+	}
+	return out, nil
+}
+
+// evalRows evaluates e against rows: aggregate function calls reduce
+// over every row in rows, while everything else (column refs, literals,
+// arithmetic) evaluates against rows[0], matching the lenient-SQL
+// convention that non-aggregated columns in an aggregate query take
+// their first group member's value.
+func (ex *executor) evalRows(e Expr, rows [][]string) (value, error) {
+	switch x := e.(type) {
+	case *NumberLit:
+		return numVal(x.Value), nil
+	case *StringLit:
+		return strVal(x.Value), nil
+	case *ColumnRef:
+		if len(rows) == 0 {
+			return strVal(""), nil
+		}
+		s := ex.cell(rows[0], x.Name)
+		if ex.colTypes[x.Name] == colDate {
+			if t, ok := parseDateValue(s); ok {
+				return dateVal(s, t), nil
+			}
+		}
+		return strVal(s), nil
+	case *UnaryExpr:
+		v, err := ex.evalRows(x.X, rows)
+		if err != nil {
+			return value{}, err
+		}
+		switch x.Op {
+		case tMinus:
+			return numVal(-v.toNum()), nil
+		case tNot:
+			return boolVal(!v.truthy()), nil
+		}
+		return value{}, fmt.Errorf("sqlengine: unknown unary operator")
+	case *BinaryExpr:
+		return ex.evalBinary(x, rows)
+	case *FuncCall:
+		return ex.evalCall(x, rows)
+	default:
+		return value{}, fmt.Errorf("sqlengine: unknown expression %T", e)
+	}
+}
+
+func (ex *executor) evalBinary(x *BinaryExpr, rows [][]string) (value, error) {
+	if x.Op == tAnd || x.Op == tOr {
+		l, err := ex.evalRows(x.L, rows)
+		if err != nil {
+			return value{}, err
+		}
+		if x.Op == tAnd && !l.truthy() {
+			return numVal(0), nil
+		}
+		if x.Op == tOr && l.truthy() {
+			return numVal(1), nil
+		}
+		r, err := ex.evalRows(x.R, rows)
+		if err != nil {
+			return value{}, err
+		}
+		return boolVal(r.truthy()), nil
+	}
+
+	l, err := ex.evalRows(x.L, rows)
+	if err != nil {
+		return value{}, err
+	}
+	r, err := ex.evalRows(x.R, rows)
+	if err != nil {
+		return value{}, err
+	}
+
+	switch x.Op {
+	case tPlus:
+		return numVal(l.toNum() + r.toNum()), nil
+	case tMinus:
+		return numVal(l.toNum() - r.toNum()), nil
+	case tStar:
+		return numVal(l.toNum() * r.toNum()), nil
+	case tSlash:
+		return numVal(l.toNum() / r.toNum()), nil
+	case tPercent:
+		li, ri := int64(l.toNum()), int64(r.toNum())
+		if ri == 0 {
+			return value{}, fmt.Errorf("sqlengine: modulo by zero")
+		}
+		return numVal(float64(li % ri)), nil
+	case tEq:
+		return boolVal(l.toStr() == r.toStr()), nil
+	case tNeq:
+		return boolVal(l.toStr() != r.toStr()), nil
+	case tLt:
+		return boolVal(l.toNum() < r.toNum()), nil
+	case tLte:
+		return boolVal(l.toNum() <= r.toNum()), nil
+	case tGt:
+		return boolVal(l.toNum() > r.toNum()), nil
+	case tGte:
+		return boolVal(l.toNum() >= r.toNum()), nil
+	case tMatch, tNotMatch:
+		re, err := ex.regexFor(r.toStr())
+		if err != nil {
+			return value{}, err
+		}
+		matched := re.MatchString(l.toStr())
+		if x.Op == tNotMatch {
+			matched = !matched
+		}
+		return boolVal(matched), nil
+	default:
+		return value{}, fmt.Errorf("sqlengine: unknown binary operator")
+	}
+}
+
+func (ex *executor) evalCall(x *FuncCall, rows [][]string) (value, error) {
+	if isAggregateFunc(x.Name) {
+		agg := newAggregate(x.Name)
+		for _, row := range rows {
+			v, err := ex.aggregateOperand(x, row)
+			if err != nil {
+				return value{}, err
+			}
+			agg.add(v)
+		}
+		return agg.result()
+	}
+
+	fn, ok := lookupScalarFunc(x.Name)
+	if !ok {
+		return value{}, fmt.Errorf("sqlengine: unknown function %q", x.Name)
+	}
+	args := make([]value, len(x.Args))
+	for i, a := range x.Args {
+		v, err := ex.evalRows(a, rows)
+		if err != nil {
+			return value{}, err
+		}
+		args[i] = v
+	}
+	return fn(args)
+}
+
+// aggregateOperand evaluates the single argument to a sum/avg/min/max
+// count call against one row of the group; count(*) (or a bare count())
+// has no real operand, so it just counts the row.
+func (ex *executor) aggregateOperand(call *FuncCall, row []string) (value, error) {
+	if len(call.Args) == 0 {
+		return numVal(1), nil
+	}
+	if cr, ok := call.Args[0].(*ColumnRef); ok && cr.Name == "*" {
+		return numVal(1), nil
+	}
+	return ex.evalRows(call.Args[0], [][]string{row})
+}
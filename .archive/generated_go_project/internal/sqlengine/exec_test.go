@@ -0,0 +1,150 @@
+package sqlengine
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"generated_go_project/internal/tableio"
+)
+
+func runQuery(t *testing.T, sql, csvInput string) [][]string {
+	t.Helper()
+	query, err := Parse(sql)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	source, err := tableio.NewSource(strings.NewReader(csvInput), tableio.CSV)
+	if err != nil {
+		t.Fatalf("NewSource: %v", err)
+	}
+	var buf bytes.Buffer
+	sink, err := tableio.NewSink(&buf, tableio.CSV)
+	if err != nil {
+		t.Fatalf("NewSink: %v", err)
+	}
+	if _, err := Execute(source, query, sink); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("sink.Close: %v", err)
+	}
+	rows, err := csvRows(buf.String())
+	if err != nil {
+		t.Fatalf("csvRows: %v", err)
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return rows[1:] // drop the output header row
+}
+
+func csvRows(s string) ([][]string, error) {
+	var rows [][]string
+	for _, line := range strings.Split(strings.TrimRight(s, "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		rows = append(rows, strings.Split(line, ","))
+	}
+	return rows, nil
+}
+
+func TestExecuteSelectWhere(t *testing.T) {
+	rows := runQuery(t, "select name from t where amount > 10", "name,amount\nalice,5\nbob,20\n")
+	if len(rows) != 1 || rows[0][0] != "bob" {
+		t.Fatalf("rows = %v, want [[bob]]", rows)
+	}
+}
+
+func TestExecuteWhereRegexMatch(t *testing.T) {
+	csv := "name,status\nalice,err_timeout\nbob,ok\ncarol,err_conn\n"
+	rows := runQuery(t, `select name from t where status ~ "^err_"`, csv)
+	if len(rows) != 2 || rows[0][0] != "alice" || rows[1][0] != "carol" {
+		t.Fatalf("rows = %v, want [[alice] [carol]]", rows)
+	}
+}
+
+func TestExecuteWhereRegexMatchReusesCompiledPattern(t *testing.T) {
+	query, err := Parse(`select name from t where status ~ "^err_"`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	source, err := tableio.NewSource(strings.NewReader("name,status\na,err_x\nb,ok\nc,err_y\n"), tableio.CSV)
+	if err != nil {
+		t.Fatalf("NewSource: %v", err)
+	}
+	var buf bytes.Buffer
+	sink, err := tableio.NewSink(&buf, tableio.CSV)
+	if err != nil {
+		t.Fatalf("NewSink: %v", err)
+	}
+	if _, err := Execute(source, query, sink); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	ex := &executor{}
+	re1, err := ex.regexFor("^err_")
+	if err != nil {
+		t.Fatalf("regexFor: %v", err)
+	}
+	re2, err := ex.regexFor("^err_")
+	if err != nil {
+		t.Fatalf("regexFor: %v", err)
+	}
+	if re1 != re2 {
+		t.Fatalf("regexFor recompiled pattern instead of reusing cached *regexp.Regexp")
+	}
+}
+
+func TestExecuteGroupByAggregate(t *testing.T) {
+	rows := runQuery(t, "select name, sum(amount) from t group by name",
+		"name,amount\nalice,5\nalice,7\nbob,20\n")
+	want := map[string]string{"alice": "12", "bob": "20"}
+	if len(rows) != 2 {
+		t.Fatalf("rows = %v, want 2 groups", rows)
+	}
+	for _, r := range rows {
+		if want[r[0]] != r[1] {
+			t.Errorf("group %q sum = %q, want %q", r[0], r[1], want[r[0]])
+		}
+	}
+}
+
+func TestExecuteOrderByDateSniffsChronological(t *testing.T) {
+	rows := runQuery(t, "select name from t order by signed_up",
+		"name,signed_up\nbob,2024-03-01\nalice,2023-11-20\n")
+	if len(rows) != 2 || rows[0][0] != "alice" || rows[1][0] != "bob" {
+		t.Fatalf("rows = %v, want [[alice] [bob]] (chronological)", rows)
+	}
+}
+
+func TestExecuteOrderByNumeric(t *testing.T) {
+	rows := runQuery(t, "select name from t order by amount desc",
+		"name,amount\nalice,5\nbob,20\ncarol,9\n")
+	if len(rows) != 3 || rows[0][0] != "bob" || rows[1][0] != "carol" || rows[2][0] != "alice" {
+		t.Fatalf("rows = %v, want [[bob] [carol] [alice]]", rows)
+	}
+}
+
+func TestExecuteAggregateCaseInsensitive(t *testing.T) {
+	rows := runQuery(t, "SELECT SUM(amount) FROM t", "name,amount\nalice,5\nbob,20\n")
+	if len(rows) != 1 || rows[0][0] != "25" {
+		t.Fatalf("rows = %v, want [[25]]", rows)
+	}
+}
+
+func TestCallScalarUpper(t *testing.T) {
+	out, err := CallScalar("upper", "mixedCase")
+	if err != nil {
+		t.Fatalf("CallScalar: %v", err)
+	}
+	if out != "MIXEDCASE" {
+		t.Errorf("CallScalar(upper) = %q, want MIXEDCASE", out)
+	}
+}
+
+func TestCallScalarUnknownFunction(t *testing.T) {
+	if _, err := CallScalar("not-a-function", "x"); err == nil {
+		t.Error("CallScalar: expected error for unknown function, got nil")
+	}
+}
@@ -0,0 +1,117 @@
+package sqlengine
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ScalarFunc is a --sql function registry entry. Registering a new name
+// here (or via RegisterFunc) extends SELECT/WHERE expressions without
+// touching the parser or evaluator.
+type ScalarFunc func(args []value) (value, error)
+
+var scalarFuncs = map[string]ScalarFunc{
+	"upper": func(args []value) (value, error) {
+		return strVal(strings.ToUpper(argStr(args, 0))), nil
+	},
+	"lower": func(args []value) (value, error) {
+		return strVal(strings.ToLower(argStr(args, 0))), nil
+	},
+	"trim": func(args []value) (value, error) {
+		return strVal(strings.TrimSpace(argStr(args, 0))), nil
+	},
+	"length": func(args []value) (value, error) {
+		return numVal(float64(len([]rune(argStr(args, 0))))), nil
+	},
+}
+
+// RegisterFunc adds or replaces a scalar function in the registry used
+// by every subsequently parsed query.
+func RegisterFunc(name string, fn ScalarFunc) {
+	scalarFuncs[name] = fn
+}
+
+func lookupScalarFunc(name string) (ScalarFunc, bool) {
+	fn, ok := scalarFuncs[strings.ToLower(name)]
+	return fn, ok
+}
+
+// CallScalar invokes the named builtin scalar function (upper, lower,
+// trim, length, ...) against string-typed args and returns its result as
+// a string. It lets callers outside a --sql query, such as the default
+// transformRecord transform, reuse the same registry --sql expressions
+// do instead of reimplementing a builtin.
+func CallScalar(name string, args ...string) (string, error) {
+	fn, ok := lookupScalarFunc(name)
+	if !ok {
+		return "", fmt.Errorf("sqlengine: unknown function %q", name)
+	}
+	vals := make([]value, len(args))
+	for i, a := range args {
+		vals[i] = strVal(a)
+	}
+	result, err := fn(vals)
+	if err != nil {
+		return "", err
+	}
+	return result.toStr(), nil
+}
+
+func argStr(args []value, i int) string {
+	if i >= len(args) {
+		return ""
+	}
+	return args[i].toStr()
+}
+
+// aggregate accumulates one GROUP BY (or whole-table) aggregate
+// function's running value across a group's rows.
+type aggregate struct {
+	kind  string
+	count int
+	sum   float64
+	min   float64
+	max   float64
+	first bool
+}
+
+func newAggregate(kind string) *aggregate {
+	return &aggregate{kind: strings.ToLower(kind), first: true}
+}
+
+func (a *aggregate) add(v value) {
+	a.count++
+	n := v.toNum()
+	if a.first {
+		a.min, a.max = n, n
+		a.first = false
+	} else {
+		if n < a.min {
+			a.min = n
+		}
+		if n > a.max {
+			a.max = n
+		}
+	}
+	a.sum += n
+}
+
+func (a *aggregate) result() (value, error) {
+	switch a.kind {
+	case "count":
+		return numVal(float64(a.count)), nil
+	case "sum":
+		return numVal(a.sum), nil
+	case "avg":
+		if a.count == 0 {
+			return numVal(0), nil
+		}
+		return numVal(a.sum / float64(a.count)), nil
+	case "min":
+		return numVal(a.min), nil
+	case "max":
+		return numVal(a.max), nil
+	default:
+		return value{}, fmt.Errorf("sqlengine: unknown aggregate %q", a.kind)
+	}
+}
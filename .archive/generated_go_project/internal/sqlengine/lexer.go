@@ -0,0 +1,210 @@
+// Package sqlengine implements a small SQL subset over an in-memory or
+// streamed table: SELECT with projections and function calls, WHERE with
+// boolean/comparison/regex predicates, GROUP BY with sum/avg/min/max/count,
+// and ORDER BY/LIMIT. It is the --sql counterpart to internal/expr's
+// AWK-style --expr language; both let a scalar function registry stand in
+// for ProcessCSV's original hardcoded uppercase transform, here as the
+// builtin upper().
+//
+// Columns have no declared schema, so types are sniffed rather than
+// enforced: int/float columns are coerced on demand by value.asNumber,
+// and ORDER BY additionally sniffs date columns from their first row
+// (see types.go) so they sort chronologically instead of
+// lexicographically. There is no first-class date type beyond ORDER BY
+// ordering (no date arithmetic or date-returning functions) and no
+// int-vs-float distinction beyond that both parse as numbers.
+package sqlengine
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tEOF tokenKind = iota
+	tNumber
+	tString
+	tIdent
+	tStar
+
+	tPlus
+	tMinus
+	tSlash
+	tPercent
+	tEq
+	tNeq
+	tLt
+	tLte
+	tGt
+	tGte
+	tMatch
+	tNotMatch
+	tLParen
+	tRParen
+	tComma
+	tDot
+
+	tSelect
+	tFrom
+	tWhere
+	tGroup
+	tOrder
+	tBy
+	tAsc
+	tDesc
+	tLimit
+	tAs
+	tAnd
+	tOr
+	tNot
+)
+
+var keywords = map[string]tokenKind{
+	"select": tSelect,
+	"from":   tFrom,
+	"where":  tWhere,
+	"group":  tGroup,
+	"order":  tOrder,
+	"by":     tBy,
+	"asc":    tAsc,
+	"desc":   tDesc,
+	"limit":  tLimit,
+	"as":     tAs,
+	"and":    tAnd,
+	"or":     tOr,
+	"not":    tNot,
+}
+
+type token struct {
+	kind tokenKind
+	text string
+	num  float64
+}
+
+type lexer struct {
+	src []rune
+	pos int
+}
+
+func newLexer(src string) *lexer { return &lexer{src: []rune(src)} }
+
+func (l *lexer) next() (token, error) {
+	l.skipSpace()
+	if l.pos >= len(l.src) {
+		return token{kind: tEOF}, nil
+	}
+	c := l.src[l.pos]
+
+	switch {
+	case c >= '0' && c <= '9':
+		return l.lexNumber(), nil
+	case c == '\'' || c == '"':
+		return l.lexString(c)
+	case isIdentStart(c):
+		return l.lexIdent(), nil
+	}
+
+	two := ""
+	if l.pos+1 < len(l.src) {
+		two = string(l.src[l.pos : l.pos+2])
+	}
+	switch two {
+	case "!=", "<>":
+		l.pos += 2
+		return token{kind: tNeq}, nil
+	case "<=":
+		l.pos += 2
+		return token{kind: tLte}, nil
+	case ">=":
+		l.pos += 2
+		return token{kind: tGte}, nil
+	case "!~":
+		l.pos += 2
+		return token{kind: tNotMatch}, nil
+	}
+
+	l.pos++
+	switch c {
+	case '*':
+		return token{kind: tStar}, nil
+	case '+':
+		return token{kind: tPlus}, nil
+	case '-':
+		return token{kind: tMinus}, nil
+	case '/':
+		return token{kind: tSlash}, nil
+	case '%':
+		return token{kind: tPercent}, nil
+	case '=':
+		return token{kind: tEq}, nil
+	case '<':
+		return token{kind: tLt}, nil
+	case '>':
+		return token{kind: tGt}, nil
+	case '~':
+		return token{kind: tMatch}, nil
+	case '(':
+		return token{kind: tLParen}, nil
+	case ')':
+		return token{kind: tRParen}, nil
+	case ',':
+		return token{kind: tComma}, nil
+	case '.':
+		return token{kind: tDot}, nil
+	}
+	return token{}, fmt.Errorf("sqlengine: unexpected character %q", c)
+}
+
+func (l *lexer) skipSpace() {
+	for l.pos < len(l.src) {
+		switch l.src[l.pos] {
+		case ' ', '\t', '\n', '\r':
+			l.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (l *lexer) lexNumber() token {
+	start := l.pos
+	for l.pos < len(l.src) && (isDigit(l.src[l.pos]) || l.src[l.pos] == '.') {
+		l.pos++
+	}
+	text := string(l.src[start:l.pos])
+	var f float64
+	fmt.Sscanf(text, "%g", &f)
+	return token{kind: tNumber, num: f, text: text}
+}
+
+func (l *lexer) lexString(quote rune) (token, error) {
+	l.pos++
+	start := l.pos
+	for l.pos < len(l.src) && l.src[l.pos] != quote {
+		l.pos++
+	}
+	if l.pos >= len(l.src) {
+		return token{}, fmt.Errorf("sqlengine: unterminated string literal")
+	}
+	text := string(l.src[start:l.pos])
+	l.pos++
+	return token{kind: tString, text: text}, nil
+}
+
+func (l *lexer) lexIdent() token {
+	start := l.pos
+	for l.pos < len(l.src) && isIdentPart(l.src[l.pos]) {
+		l.pos++
+	}
+	text := string(l.src[start:l.pos])
+	if kind, ok := keywords[strings.ToLower(text)]; ok {
+		return token{kind: kind, text: text}
+	}
+	return token{kind: tIdent, text: text}
+}
+
+func isDigit(c rune) bool      { return c >= '0' && c <= '9' }
+func isIdentStart(c rune) bool { return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') }
+func isIdentPart(c rune) bool  { return isIdentStart(c) || isDigit(c) }
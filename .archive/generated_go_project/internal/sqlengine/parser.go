@@ -0,0 +1,360 @@
+package sqlengine
+
+import "fmt"
+
+// Parse compiles a --sql statement into a Query.
+func Parse(src string) (*Query, error) {
+	p := &parser{lex: newLexer(src)}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	return p.parseQuery()
+}
+
+type parser struct {
+	lex *lexer
+	tok token
+}
+
+func (p *parser) advance() error {
+	t, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = t
+	return nil
+}
+
+func (p *parser) expect(k tokenKind, what string) error {
+	if p.tok.kind != k {
+		return fmt.Errorf("sqlengine: expected %s", what)
+	}
+	return p.advance()
+}
+
+func (p *parser) parseQuery() (*Query, error) {
+	if err := p.expect(tSelect, "SELECT"); err != nil {
+		return nil, err
+	}
+	q := &Query{Limit: -1}
+
+	items, err := p.parseSelectList()
+	if err != nil {
+		return nil, err
+	}
+	q.Select = items
+
+	if err := p.expect(tFrom, "FROM"); err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tIdent {
+		return nil, fmt.Errorf("sqlengine: expected table name after FROM")
+	}
+	q.From = p.tok.text
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	if p.tok.kind == tWhere {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		where, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		q.Where = where
+	}
+
+	if p.tok.kind == tGroup {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if err := p.expect(tBy, "BY"); err != nil {
+			return nil, err
+		}
+		cols, err := p.parseIdentList()
+		if err != nil {
+			return nil, err
+		}
+		q.GroupBy = cols
+	}
+
+	if p.tok.kind == tOrder {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if err := p.expect(tBy, "BY"); err != nil {
+			return nil, err
+		}
+		items, err := p.parseOrderList()
+		if err != nil {
+			return nil, err
+		}
+		q.OrderBy = items
+	}
+
+	if p.tok.kind == tLimit {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tNumber {
+			return nil, fmt.Errorf("sqlengine: expected number after LIMIT")
+		}
+		q.Limit = int(p.tok.num)
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.tok.kind != tEOF {
+		return nil, fmt.Errorf("sqlengine: unexpected trailing input")
+	}
+	return q, nil
+}
+
+func (p *parser) parseSelectList() ([]SelectItem, error) {
+	var items []SelectItem
+	for {
+		if p.tok.kind == tStar {
+			items = append(items, SelectItem{Star: true})
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+		} else {
+			e, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			alias := exprDefaultAlias(e)
+			if p.tok.kind == tAs {
+				if err := p.advance(); err != nil {
+					return nil, err
+				}
+				if p.tok.kind != tIdent {
+					return nil, fmt.Errorf("sqlengine: expected alias after AS")
+				}
+				alias = p.tok.text
+				if err := p.advance(); err != nil {
+					return nil, err
+				}
+			}
+			items = append(items, SelectItem{Expr: e, Alias: alias})
+		}
+		if p.tok.kind != tComma {
+			break
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+	return items, nil
+}
+
+func exprDefaultAlias(e Expr) string {
+	switch x := e.(type) {
+	case *ColumnRef:
+		return x.Name
+	case *FuncCall:
+		return x.Name
+	default:
+		return "expr"
+	}
+}
+
+func (p *parser) parseIdentList() ([]string, error) {
+	var names []string
+	for {
+		if p.tok.kind != tIdent {
+			return nil, fmt.Errorf("sqlengine: expected column name")
+		}
+		names = append(names, p.tok.text)
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tComma {
+			break
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+	return names, nil
+}
+
+func (p *parser) parseOrderList() ([]OrderItem, error) {
+	var items []OrderItem
+	for {
+		e, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		desc := false
+		if p.tok.kind == tAsc {
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+		} else if p.tok.kind == tDesc {
+			desc = true
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+		}
+		items = append(items, OrderItem{Expr: e, Desc: desc})
+		if p.tok.kind != tComma {
+			break
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+	return items, nil
+}
+
+func (p *parser) parseExpr() (Expr, error) { return p.parseOr() }
+
+func (p *parser) parseOr() (Expr, error) {
+	return p.parseBinaryLevel([]tokenKind{tOr}, p.parseAnd)
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	return p.parseBinaryLevel([]tokenKind{tAnd}, p.parseNot)
+}
+
+func (p *parser) parseNot() (Expr, error) {
+	if p.tok.kind == tNot {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		x, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &UnaryExpr{Op: tNot, X: x}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (Expr, error) {
+	return p.parseBinaryLevel([]tokenKind{tEq, tNeq, tLt, tLte, tGt, tGte, tMatch, tNotMatch}, p.parseAdditive)
+}
+
+func (p *parser) parseAdditive() (Expr, error) {
+	return p.parseBinaryLevel([]tokenKind{tPlus, tMinus}, p.parseMultiplicative)
+}
+
+func (p *parser) parseMultiplicative() (Expr, error) {
+	return p.parseBinaryLevel([]tokenKind{tStar, tSlash, tPercent}, p.parseUnary)
+}
+
+func (p *parser) parseBinaryLevel(ops []tokenKind, next func() (Expr, error)) (Expr, error) {
+	lhs, err := next()
+	if err != nil {
+		return nil, err
+	}
+	for containsKind(ops, p.tok.kind) {
+		op := p.tok.kind
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		rhs, err := next()
+		if err != nil {
+			return nil, err
+		}
+		lhs = &BinaryExpr{Op: op, L: lhs, R: rhs}
+	}
+	return lhs, nil
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	if p.tok.kind == tMinus {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &UnaryExpr{Op: tMinus, X: x}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Expr, error) {
+	switch p.tok.kind {
+	case tNumber:
+		v := p.tok.num
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		return &NumberLit{Value: v}, nil
+	case tString:
+		v := p.tok.text
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		return &StringLit{Value: v}, nil
+	case tIdent:
+		name := p.tok.text
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if p.tok.kind == tLParen {
+			return p.parseCall(name)
+		}
+		return &ColumnRef{Name: name}, nil
+	case tLParen:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		e, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tRParen, "')'"); err != nil {
+			return nil, err
+		}
+		return e, nil
+	case tStar:
+		// count(*) is handled by parseCall seeing a bare '*' argument.
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		return &ColumnRef{Name: "*"}, nil
+	}
+	return nil, fmt.Errorf("sqlengine: unexpected token in expression")
+}
+
+func (p *parser) parseCall(name string) (Expr, error) {
+	if err := p.advance(); err != nil { // consume '('
+		return nil, err
+	}
+	var args []Expr
+	for p.tok.kind != tRParen {
+		e, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, e)
+		if p.tok.kind == tComma {
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		break
+	}
+	if err := p.expect(tRParen, "')'"); err != nil {
+		return nil, err
+	}
+	return &FuncCall{Name: name, Args: args}, nil
+}
+
+func containsKind(ks []tokenKind, k tokenKind) bool {
+	for _, x := range ks {
+		if x == k {
+			return true
+		}
+	}
+	return false
+}
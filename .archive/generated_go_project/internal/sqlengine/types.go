@@ -0,0 +1,61 @@
+package sqlengine
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// colType is a column's sniffed type, inferred from a sample row rather
+// than declared, since --sql has no schema of its own to consult.
+type colType int
+
+const (
+	colString colType = iota
+	colInt
+	colFloat
+	colDate
+)
+
+// dateLayouts are tried in order when sniffing whether a column holds
+// dates, so ORDER BY can sort them chronologically instead of
+// lexicographically.
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// parseDateValue tries every layout in dateLayouts against s, trimmed of
+// surrounding whitespace, and reports the first one that parses.
+func parseDateValue(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// sniffColumnType classifies a single sample value as int, float, date or
+// (the default) string. It is deliberately lenient: a column is typed
+// from one sample row, matching the same "coerce on demand, don't
+// enforce" spirit as value.asNumber.
+func sniffColumnType(sample string) colType {
+	sample = strings.TrimSpace(sample)
+	if sample == "" {
+		return colString
+	}
+	if _, err := strconv.ParseInt(sample, 10, 64); err == nil {
+		return colInt
+	}
+	if _, err := strconv.ParseFloat(sample, 64); err == nil {
+		return colFloat
+	}
+	if _, ok := parseDateValue(sample); ok {
+		return colDate
+	}
+	return colString
+}
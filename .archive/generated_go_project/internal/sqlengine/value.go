@@ -0,0 +1,72 @@
+package sqlengine
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// value is either a float64, a string or (when the owning column sniffed
+// as colDate, see types.go) a parsed date; a cell's string value is
+// coerced to a number on demand rather than sniffed column-wide ahead of
+// time, so the same evaluator works for both the streaming and the
+// materialized (GROUP BY/ORDER BY) execution paths. Date values keep
+// their original string form too, so toStr() round-trips the input
+// formatting instead of reformatting it.
+type value struct {
+	num    float64
+	str    string
+	isStr  bool
+	isDate bool
+	date   time.Time
+}
+
+func numVal(f float64) value { return value{num: f} }
+func strVal(s string) value  { return value{str: s, isStr: true} }
+
+// dateVal wraps a value that sniffed as colDate, keeping its original
+// string form (for toStr()) alongside the parsed time (for comparison).
+func dateVal(s string, t time.Time) value {
+	return value{str: s, isStr: true, isDate: true, date: t}
+}
+
+func (v value) toNum() float64 {
+	if !v.isStr {
+		return v.num
+	}
+	f, _ := strconv.ParseFloat(strings.TrimSpace(v.str), 64)
+	return f
+}
+
+func (v value) toStr() string {
+	if v.isStr {
+		return v.str
+	}
+	return strconv.FormatFloat(v.num, 'f', -1, 64)
+}
+
+func (v value) truthy() bool {
+	if v.isStr {
+		return v.str != ""
+	}
+	return v.num != 0
+}
+
+// asNumber reports whether v is (or looks like) a number, along with its
+// numeric value; used by ORDER BY so numeric columns sort numerically
+// rather than lexicographically even though column values arrive typed
+// as strings.
+func (v value) asNumber() (float64, bool) {
+	if !v.isStr {
+		return v.num, true
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(v.str), 64)
+	return f, err == nil
+}
+
+func boolVal(b bool) value {
+	if b {
+		return numVal(1)
+	}
+	return numVal(0)
+}
@@ -0,0 +1,64 @@
+package tableio
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// delimitedSource implements Source for comma- or tab-separated text,
+// reusing encoding/csv with the same lenient settings ProcessCSV has
+// always used (variable field counts, lazy quotes, leading-space trim).
+type delimitedSource struct {
+	reader *csv.Reader
+	header []string
+}
+
+func newDelimitedSource(r io.Reader, comma rune) *delimitedSource {
+	cr := csv.NewReader(r)
+	cr.Comma = comma
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	cr.TrimLeadingSpace = true
+	return &delimitedSource{reader: cr}
+}
+
+func (s *delimitedSource) Header() []string {
+	if s.header == nil {
+		header, err := s.reader.Read()
+		if err != nil {
+			return nil
+		}
+		s.header = header
+	}
+	return s.header
+}
+
+func (s *delimitedSource) Next() ([]string, error) {
+	s.Header() // ensure the header line has been consumed
+	return s.reader.Read()
+}
+
+func (s *delimitedSource) Close() error { return nil }
+
+type delimitedSink struct {
+	writer *csv.Writer
+}
+
+func newDelimitedSink(w io.Writer, comma rune) *delimitedSink {
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	return &delimitedSink{writer: cw}
+}
+
+func (s *delimitedSink) WriteHeader(header []string) error {
+	return s.writer.Write(header)
+}
+
+func (s *delimitedSink) Write(row []string) error {
+	return s.writer.Write(row)
+}
+
+func (s *delimitedSink) Close() error {
+	s.writer.Flush()
+	return s.writer.Error()
+}
@@ -0,0 +1,173 @@
+package tableio
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+)
+
+// jsonSource reads a JSON array of flat objects. Row values are
+// stringified so the rest of the pipeline can keep treating every format
+// as []string rows; on the way back out, rowToOrderedObject sniffs
+// num/bool/null back out of those strings, but that sniffing is
+// heuristic (see its doc comment) since the real type was discarded on
+// the way in. JSON objects are unordered,
+// so the column order is taken as the sorted keys of the first object;
+// every row downstream is expected to share that object's schema, the
+// same assumption CSV/TSV sources make about their header row.
+type jsonSource struct {
+	rows []map[string]interface{}
+	pos  int
+	hdr  []string
+}
+
+func newJSONSource(r io.Reader) (*jsonSource, error) {
+	var raw []map[string]interface{}
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("tableio: decode JSON array: %w", err)
+	}
+	var hdr []string
+	if len(raw) > 0 {
+		for k := range raw[0] {
+			hdr = append(hdr, k)
+		}
+		sort.Strings(hdr)
+	}
+	return &jsonSource{rows: raw, hdr: hdr}, nil
+}
+
+func (s *jsonSource) Header() []string { return s.hdr }
+
+func (s *jsonSource) Next() ([]string, error) {
+	if s.pos >= len(s.rows) {
+		return nil, io.EOF
+	}
+	obj := s.rows[s.pos]
+	s.pos++
+	row := make([]string, len(s.hdr))
+	for i, k := range s.hdr {
+		row[i] = stringifyJSONValue(obj[k])
+	}
+	return row, nil
+}
+
+func (s *jsonSource) Close() error { return nil }
+
+func stringifyJSONValue(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	default:
+		b, _ := json.Marshal(x)
+		return string(b)
+	}
+}
+
+// jsonSink writes rows as a JSON array of objects keyed by the sink's
+// header, formatted one object per line for readability.
+type jsonSink struct {
+	w      *bufio.Writer
+	header []string
+	n      int
+}
+
+func newJSONSink(w io.Writer) *jsonSink {
+	return &jsonSink{w: bufio.NewWriter(w)}
+}
+
+func (s *jsonSink) WriteHeader(header []string) error {
+	s.header = header
+	_, err := s.w.WriteString("[\n")
+	return err
+}
+
+func (s *jsonSink) Write(row []string) error {
+	if s.n > 0 {
+		if _, err := s.w.WriteString(",\n"); err != nil {
+			return err
+		}
+	}
+	obj := rowToOrderedObject(s.header, row)
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	if _, err := s.w.WriteString("  "); err != nil {
+		return err
+	}
+	if _, err := s.w.Write(b); err != nil {
+		return err
+	}
+	s.n++
+	return nil
+}
+
+func (s *jsonSink) Close() error {
+	if _, err := s.w.WriteString("\n]\n"); err != nil {
+		return err
+	}
+	return s.w.Flush()
+}
+
+// rowToOrderedObject renders header/row as a JSON object literal that
+// preserves column order (encoding/json sorts map keys, so this builds
+// the object body by hand rather than marshaling a map).
+//
+// Every row in this package's pipeline is a []string: there is no
+// per-column type carried alongside it, so a cell that was a JSON number
+// or boolean on the way in is, by the time it reaches here, indistinguishable
+// from a string that merely looks like one. jsonScalarLiteral sniffs
+// num/bool/null by regex and writes those unquoted rather than quoting
+// every value, which fixes the common case (round-tripping
+// {"age":30} does not become {"age":"30"}) at the cost of an inherent
+// false positive: a genuinely string-typed column whose values happen to
+// look numeric or boolean (an order ID of "42", a status column
+// containing the literal word "true") comes out unquoted too. Fixing
+// that for real needs typed rows, not a smarter regex.
+func rowToOrderedObject(header, row []string) json.RawMessage {
+	var buf []byte
+	buf = append(buf, '{')
+	for i, col := range header {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		key, _ := json.Marshal(col)
+		buf = append(buf, key...)
+		buf = append(buf, ':')
+		var val string
+		if i < len(row) {
+			val = row[i]
+		}
+		if lit, ok := jsonScalarLiteral(val); ok {
+			buf = append(buf, lit...)
+		} else {
+			value, _ := json.Marshal(val)
+			buf = append(buf, value...)
+		}
+	}
+	buf = append(buf, '}')
+	return buf
+}
+
+// jsonNumberPattern matches a JSON number literal (RFC 8259 grammar).
+var jsonNumberPattern = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// jsonScalarLiteral reports whether val should be written as a bare JSON
+// number/bool literal rather than a quoted string, returning that
+// literal. Empty cells stay quoted as "" rather than becoming null: a
+// blank CSV cell is ordinarily an empty string, not JSON null.
+func jsonScalarLiteral(val string) (string, bool) {
+	switch val {
+	case "true", "false", "null":
+		return val, true
+	}
+	if jsonNumberPattern.MatchString(val) {
+		return val, true
+	}
+	return "", false
+}
@@ -0,0 +1,81 @@
+package tableio
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestJSONSinkPreservesNumericAndBoolTypes(t *testing.T) {
+	var buf bytes.Buffer
+	sink := newJSONSink(&buf)
+	if err := sink.WriteHeader([]string{"name", "age", "active", "zip"}); err != nil {
+		t.Fatalf("WriteHeader: %v", err)
+	}
+	if err := sink.Write([]string{"alice", "30", "true", "007"}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	got := buf.String()
+	if !strings.Contains(got, `"age":30`) {
+		t.Errorf("output missing unquoted age:30: %s", got)
+	}
+	if !strings.Contains(got, `"active":true`) {
+		t.Errorf("output missing unquoted active:true: %s", got)
+	}
+	if !strings.Contains(got, `"name":"alice"`) {
+		t.Errorf("output missing quoted name: %s", got)
+	}
+}
+
+func TestJSONSourceSinkRoundTripsNumbers(t *testing.T) {
+	source, err := newJSONSource(strings.NewReader(`[{"age":30,"name":"alice"}]`))
+	if err != nil {
+		t.Fatalf("newJSONSource: %v", err)
+	}
+	row, err := source.Next()
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+
+	var buf bytes.Buffer
+	sink := newJSONSink(&buf)
+	if err := sink.WriteHeader(source.Header()); err != nil {
+		t.Fatalf("WriteHeader: %v", err)
+	}
+	if err := sink.Write(row); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"age":30`) {
+		t.Errorf("round-tripped output quoted a number: %s", buf.String())
+	}
+}
+
+func TestJSONScalarLiteralNonNumericStringsStayQuoted(t *testing.T) {
+	if _, ok := jsonScalarLiteral("alice"); ok {
+		t.Error(`jsonScalarLiteral("alice") should not be treated as a literal`)
+	}
+}
+
+// TestJSONScalarLiteralStringIDFalsePositive documents the known,
+// inherent false positive from jsonScalarLiteral's doc comment: a
+// string-typed column whose value happens to look like a plain number
+// (an order ID of "42", not a count) is written unquoted too, since
+// []string rows carry no real column type to disambiguate. A value with
+// a leading zero like "007" is not a valid JSON number literal, so that
+// particular case stays quoted.
+func TestJSONScalarLiteralStringIDFalsePositive(t *testing.T) {
+	lit, ok := jsonScalarLiteral("42")
+	if !ok || lit != "42" {
+		t.Fatalf(`jsonScalarLiteral("42") = %q, %v, want "42", true`, lit, ok)
+	}
+	if lit, ok := jsonScalarLiteral("007"); ok {
+		t.Errorf(`jsonScalarLiteral("007") = %q, true, want false (not a valid JSON number literal)`, lit)
+	}
+}
@@ -0,0 +1,88 @@
+package tableio
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// jsonLinesSource reads one flat JSON object per line. As with jsonSource,
+// the column order is the sorted keys of the first line's object.
+type jsonLinesSource struct {
+	scanner *bufio.Scanner
+	hdr     []string
+	pending map[string]interface{}
+}
+
+func newJSONLinesSource(r io.Reader) (*jsonLinesSource, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	s := &jsonLinesSource{scanner: scanner}
+	if scanner.Scan() {
+		var obj map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &obj); err != nil {
+			return nil, fmt.Errorf("tableio: decode JSONL line: %w", err)
+		}
+		for k := range obj {
+			s.hdr = append(s.hdr, k)
+		}
+		sort.Strings(s.hdr)
+		s.pending = obj
+	}
+	return s, scanner.Err()
+}
+
+func (s *jsonLinesSource) Header() []string { return s.hdr }
+
+func (s *jsonLinesSource) Next() ([]string, error) {
+	var obj map[string]interface{}
+	if s.pending != nil {
+		obj = s.pending
+		s.pending = nil
+	} else {
+		if !s.scanner.Scan() {
+			if err := s.scanner.Err(); err != nil {
+				return nil, err
+			}
+			return nil, io.EOF
+		}
+		if err := json.Unmarshal(s.scanner.Bytes(), &obj); err != nil {
+			return nil, fmt.Errorf("tableio: decode JSONL line: %w", err)
+		}
+	}
+	row := make([]string, len(s.hdr))
+	for i, k := range s.hdr {
+		row[i] = stringifyJSONValue(obj[k])
+	}
+	return row, nil
+}
+
+func (s *jsonLinesSource) Close() error { return nil }
+
+type jsonLinesSink struct {
+	w      *bufio.Writer
+	header []string
+}
+
+func newJSONLinesSink(w io.Writer) *jsonLinesSink {
+	return &jsonLinesSink{w: bufio.NewWriter(w)}
+}
+
+func (s *jsonLinesSink) WriteHeader(header []string) error {
+	s.header = header
+	return nil
+}
+
+func (s *jsonLinesSink) Write(row []string) error {
+	obj := rowToOrderedObject(s.header, row)
+	if _, err := s.w.Write(obj); err != nil {
+		return err
+	}
+	return s.w.WriteByte('\n')
+}
+
+func (s *jsonLinesSink) Close() error {
+	return s.w.Flush()
+}
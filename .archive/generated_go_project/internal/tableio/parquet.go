@@ -0,0 +1,152 @@
+package tableio
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// Parquet support round-trips the same []string rows every other format
+// in this package does: every column is written as an optional string
+// (parquet.Optional(parquet.String())), so there is no int/float/bool
+// column typing, just like the CSV/TSV/XLSX sources this package already
+// has. That is enough to make --output out.parquet produce a file real
+// tools (e.g. Spark, DuckDB, pandas) can read, at the cost of every
+// column coming back out as a string column rather than whatever type
+// the original data had.
+//
+// parquet.Group is a map, so parquet-go's schema walks its fields in
+// name order rather than insertion order; without correcting for that, a
+// header like [name amount] would silently round-trip as [amount name].
+// parquetColumnsKey stashes the original column order as file metadata
+// on write, and newParquetSource consults it on read, falling back to
+// the (alphabetical) schema order for files this package did not write.
+const parquetColumnsKey = "tableio-columns"
+
+// parquetColumnSep separates column names in the parquetColumnsKey
+// metadata value; chosen to be a byte column names can't realistically
+// contain.
+const parquetColumnSep = "\x1f"
+
+// parquetSource eagerly decodes every row group into memory, the same
+// way newXLSXSource and newJSONSource do: parquet.OpenFile needs an
+// io.ReaderAt with a known size, and a streaming Source interface gives
+// us neither up front.
+type parquetSource struct {
+	rows [][]string
+	pos  int
+	hdr  []string
+}
+
+func newParquetSource(r io.Reader) (*parquetSource, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	pf, err := parquet.OpenFile(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("tableio: not a valid parquet file: %w", err)
+	}
+
+	cols := pf.Schema().Columns()
+	schemaOrder := make([]string, len(cols))
+	for i, col := range cols {
+		schemaOrder[i] = col[len(col)-1]
+	}
+
+	hdr := schemaOrder
+	if stored, ok := pf.Lookup(parquetColumnsKey); ok {
+		hdr = strings.Split(stored, parquetColumnSep)
+	}
+
+	var rows [][]string
+	for _, rg := range pf.RowGroups() {
+		rowReader := rg.Rows()
+		buf := make([]parquet.Row, 128)
+		for {
+			n, err := rowReader.ReadRows(buf)
+			for i := 0; i < n; i++ {
+				byName := make(map[string]string, len(schemaOrder))
+				buf[i].Range(func(columnIndex int, values []parquet.Value) bool {
+					if len(values) > 0 && !values[0].IsNull() {
+						byName[schemaOrder[columnIndex]] = values[0].String()
+					}
+					return true
+				})
+				row := make([]string, len(hdr))
+				for j, name := range hdr {
+					row[j] = byName[name]
+				}
+				rows = append(rows, row)
+			}
+			if err != nil {
+				if err != io.EOF {
+					rowReader.Close()
+					return nil, fmt.Errorf("tableio: read parquet row group: %w", err)
+				}
+				break
+			}
+		}
+		if err := rowReader.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &parquetSource{rows: rows, hdr: hdr}, nil
+}
+
+func (s *parquetSource) Header() []string { return s.hdr }
+
+func (s *parquetSource) Next() ([]string, error) {
+	if s.pos >= len(s.rows) {
+		return nil, io.EOF
+	}
+	row := s.rows[s.pos]
+	s.pos++
+	return row, nil
+}
+
+func (s *parquetSource) Close() error { return nil }
+
+// parquetSink writes rows as they arrive: unlike xlsxSink, a parquet
+// Writer streams pages to w incrementally and only needs to finalize the
+// footer on Close, so there is no need to buffer every row in memory
+// first.
+type parquetSink struct {
+	w      io.Writer
+	header []string
+	pw     *parquet.Writer
+}
+
+func newParquetSink(w io.Writer) *parquetSink {
+	return &parquetSink{w: w}
+}
+
+func (s *parquetSink) WriteHeader(header []string) error {
+	s.header = header
+	group := make(parquet.Group, len(header))
+	for _, col := range header {
+		group[col] = parquet.Optional(parquet.String())
+	}
+	schema := parquet.NewSchema("row", group)
+	s.pw = parquet.NewWriter(s.w, schema,
+		parquet.KeyValueMetadata(parquetColumnsKey, strings.Join(header, parquetColumnSep)))
+	return nil
+}
+
+func (s *parquetSink) Write(row []string) error {
+	obj := make(map[string]string, len(s.header))
+	for i, col := range s.header {
+		if i < len(row) {
+			obj[col] = row[i]
+		}
+	}
+	return s.pw.Write(obj)
+}
+
+func (s *parquetSink) Close() error {
+	return s.pw.Close()
+}
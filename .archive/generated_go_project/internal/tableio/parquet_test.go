@@ -0,0 +1,90 @@
+package tableio
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParquetSourceSinkRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	sink, err := NewSink(&buf, Parquet)
+	if err != nil {
+		t.Fatalf("NewSink: %v", err)
+	}
+	header := []string{"name", "amount"}
+	if err := sink.WriteHeader(header); err != nil {
+		t.Fatalf("WriteHeader: %v", err)
+	}
+	rows := [][]string{
+		{"alice", "5"},
+		{"bob", "20"},
+	}
+	for _, row := range rows {
+		if err := sink.Write(row); err != nil {
+			t.Fatalf("Write(%v): %v", row, err)
+		}
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	source, err := NewSource(bytes.NewReader(buf.Bytes()), Parquet)
+	if err != nil {
+		t.Fatalf("NewSource: %v", err)
+	}
+	if got := source.Header(); !equalStrings(got, header) {
+		t.Errorf("Header = %v, want %v", got, header)
+	}
+	for i, want := range rows {
+		got, err := source.Next()
+		if err != nil {
+			t.Fatalf("Next(%d): %v", i, err)
+		}
+		if !equalStrings(got, want) {
+			t.Errorf("row %d = %v, want %v", i, got, want)
+		}
+	}
+	if _, err := source.Next(); err == nil {
+		t.Error("Next: expected io.EOF after the last row, got nil")
+	}
+}
+
+func TestParquetSourceMissingColumnComesBackEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	sink, err := NewSink(&buf, Parquet)
+	if err != nil {
+		t.Fatalf("NewSink: %v", err)
+	}
+	header := []string{"a", "b"}
+	if err := sink.WriteHeader(header); err != nil {
+		t.Fatalf("WriteHeader: %v", err)
+	}
+	if err := sink.Write([]string{"1"}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	source, err := NewSource(bytes.NewReader(buf.Bytes()), Parquet)
+	if err != nil {
+		t.Fatalf("NewSource: %v", err)
+	}
+	row, err := source.Next()
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if want := []string{"1", ""}; !equalStrings(row, want) {
+		t.Errorf("row = %v, want %v", row, want)
+	}
+}
+
+func TestDetectFormatRecognizesParquetExtension(t *testing.T) {
+	f, err := DetectFormat("out.parquet")
+	if err != nil {
+		t.Fatalf("DetectFormat: %v", err)
+	}
+	if f != Parquet {
+		t.Errorf("DetectFormat(out.parquet) = %v, want Parquet", f)
+	}
+}
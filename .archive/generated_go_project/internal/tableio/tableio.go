@@ -0,0 +1,143 @@
+// Package tableio abstracts the tabular formats ProcessCSV can read and
+// write behind Source and Sink interfaces, so the rest of the pipeline
+// (worker pool, transform, --expr) stays format-agnostic. Supported
+// formats are CSV, TSV, JSON (array of objects), JSON Lines, a minimal
+// XLSX reader/writer, and Parquet (via github.com/parquet-go/parquet-go,
+// see parquet.go).
+//
+// Every column round-trips as a string, the same tradeoff CSV/TSV/XLSX
+// already make: Parquet's reader/writer treat every column as an
+// optional string column, so a file written by this package carries no
+// int/float/bool typing of its own, even though the format supports it.
+package tableio
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// Source reads a table row by row.
+type Source interface {
+	// Header returns the column names, in column order.
+	Header() []string
+	// Next returns the next row, or io.EOF when the table is exhausted.
+	Next() ([]string, error)
+	Close() error
+}
+
+// Sink writes a table row by row.
+type Sink interface {
+	// WriteHeader must be called exactly once, before any Write calls.
+	WriteHeader([]string) error
+	Write([]string) error
+	Close() error
+}
+
+// Format identifies a tabular file format.
+type Format int
+
+const (
+	CSV Format = iota
+	TSV
+	JSON
+	JSONLines
+	XLSX
+	Parquet
+)
+
+func (f Format) String() string {
+	switch f {
+	case CSV:
+		return "csv"
+	case TSV:
+		return "tsv"
+	case JSON:
+		return "json"
+	case JSONLines:
+		return "jsonl"
+	case XLSX:
+		return "xlsx"
+	case Parquet:
+		return "parquet"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseFormat maps a --input-format/--output-format flag value, or a
+// file extension (with or without the leading dot), to a Format.
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(strings.TrimPrefix(name, ".")) {
+	case "csv":
+		return CSV, nil
+	case "tsv":
+		return TSV, nil
+	case "json":
+		return JSON, nil
+	case "jsonl", "ndjson":
+		return JSONLines, nil
+	case "xlsx":
+		return XLSX, nil
+	case "parquet":
+		return Parquet, nil
+	default:
+		return 0, fmt.Errorf("tableio: unknown format %q", name)
+	}
+}
+
+// DetectFormat derives a Format from path's extension.
+func DetectFormat(path string) (Format, error) {
+	return ParseFormat(filepath.Ext(path))
+}
+
+// IsBinary reports whether a format's bytes must not be passed through
+// the text-oriented charset autodetect/transcode stage (see
+// internal/charset): XLSX is a zip archive and Parquet a binary
+// columnar format, so neither is text in the relevant sense.
+func (f Format) IsBinary() bool {
+	return f == XLSX || f == Parquet
+}
+
+// NewSource opens path for reading as format, wrapping the already
+// format-appropriate reader r (e.g. charset-transcoded for text
+// formats, raw for binary ones).
+func NewSource(r io.Reader, format Format) (Source, error) {
+	switch format {
+	case CSV:
+		return newDelimitedSource(r, ','), nil
+	case TSV:
+		return newDelimitedSource(r, '\t'), nil
+	case JSON:
+		return newJSONSource(r)
+	case JSONLines:
+		return newJSONLinesSource(r)
+	case XLSX:
+		return newXLSXSource(r)
+	case Parquet:
+		return newParquetSource(r)
+	default:
+		return nil, fmt.Errorf("tableio: unsupported source format %v", format)
+	}
+}
+
+// NewSink opens w for writing as format.
+func NewSink(w io.Writer, format Format) (Sink, error) {
+	switch format {
+	case CSV:
+		return newDelimitedSink(w, ','), nil
+	case TSV:
+		return newDelimitedSink(w, '\t'), nil
+	case JSON:
+		return newJSONSink(w), nil
+	case JSONLines:
+		return newJSONLinesSink(w), nil
+	case XLSX:
+		return newXLSXSink(w), nil
+	case Parquet:
+		return newParquetSink(w), nil
+	default:
+		return nil, fmt.Errorf("tableio: unsupported sink format %v", format)
+	}
+}
@@ -0,0 +1,261 @@
+package tableio
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// This file implements just enough of the OOXML spreadsheet format to
+// round-trip a single flat sheet: reading resolves shared strings and
+// inline strings on the first worksheet, writing emits inline strings
+// so no sharedStrings.xml part is needed. Multiple sheets, styles,
+// formulas and merged cells are out of scope.
+
+type xlsxRow struct {
+	Cells []xlsxCell `xml:"c"`
+}
+
+type xlsxCell struct {
+	Ref    string      `xml:"r,attr"`
+	Type   string      `xml:"t,attr"`
+	Value  string      `xml:"v"`
+	Inline *xlsxInline `xml:"is"`
+}
+
+type xlsxInline struct {
+	Text string `xml:"t"`
+}
+
+type xlsxSheetData struct {
+	Rows []xlsxRow `xml:"sheetData>row"`
+}
+
+type sharedStrings struct {
+	Items []struct {
+		Text string `xml:"t"`
+	} `xml:"si"`
+}
+
+type xlsxSource struct {
+	rows [][]string
+	pos  int
+	hdr  []string
+}
+
+func newXLSXSource(r io.Reader) (*xlsxSource, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("tableio: not a valid xlsx (zip) file: %w", err)
+	}
+
+	var strings []string
+	if f := findZipFile(zr, "xl/sharedStrings.xml"); f != nil {
+		var ss sharedStrings
+		if err := decodeZipXML(f, &ss); err != nil {
+			return nil, err
+		}
+		for _, it := range ss.Items {
+			strings = append(strings, it.Text)
+		}
+	}
+
+	sheet := findZipFile(zr, "xl/worksheets/sheet1.xml")
+	if sheet == nil {
+		return nil, fmt.Errorf("tableio: xlsx file has no xl/worksheets/sheet1.xml")
+	}
+	var sd xlsxSheetData
+	if err := decodeZipXML(sheet, &sd); err != nil {
+		return nil, err
+	}
+
+	var rows [][]string
+	for _, xr := range sd.Rows {
+		var row []string
+		for _, c := range xr.Cells {
+			col := xlsxColumnIndex(c.Ref)
+			if col < 0 {
+				col = len(row)
+			}
+			for len(row) <= col {
+				row = append(row, "")
+			}
+			switch c.Type {
+			case "s":
+				idx, _ := strconv.Atoi(c.Value)
+				if idx >= 0 && idx < len(strings) {
+					row[col] = strings[idx]
+				}
+			case "inlineStr":
+				if c.Inline != nil {
+					row[col] = c.Inline.Text
+				}
+			default:
+				row[col] = c.Value
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	src := &xlsxSource{rows: rows}
+	if len(rows) > 0 {
+		src.hdr = rows[0]
+		src.rows = rows[1:]
+	}
+	return src, nil
+}
+
+// xlsxColumnIndex converts a cell reference's column letters (e.g. the
+// "B" in "B2", the "AA" in "AA10") into a 0-based column index. Real
+// XLSX writers omit <c> elements for blank cells entirely rather than
+// emitting an empty one, so the column letters in Ref are the only
+// reliable way to place a cell in its row; a malformed/missing Ref
+// falls back to 0 rather than panicking.
+func xlsxColumnIndex(ref string) int {
+	idx := 0
+	for _, ch := range ref {
+		if ch < 'A' || ch > 'Z' {
+			break
+		}
+		idx = idx*26 + int(ch-'A') + 1
+	}
+	return idx - 1
+}
+
+func findZipFile(zr *zip.Reader, name string) *zip.File {
+	for _, f := range zr.File {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+func decodeZipXML(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return xml.NewDecoder(rc).Decode(v)
+}
+
+func (s *xlsxSource) Header() []string { return s.hdr }
+
+func (s *xlsxSource) Next() ([]string, error) {
+	if s.pos >= len(s.rows) {
+		return nil, io.EOF
+	}
+	row := s.rows[s.pos]
+	s.pos++
+	return row, nil
+}
+
+func (s *xlsxSource) Close() error { return nil }
+
+// xlsxSink buffers rows in memory and writes a minimal single-sheet
+// workbook on Close, since the zip central directory can only be
+// finalized once every part's size is known.
+type xlsxSink struct {
+	w      io.Writer
+	header []string
+	rows   [][]string
+}
+
+func newXLSXSink(w io.Writer) *xlsxSink {
+	return &xlsxSink{w: w}
+}
+
+func (s *xlsxSink) WriteHeader(header []string) error {
+	s.header = header
+	return nil
+}
+
+func (s *xlsxSink) Write(row []string) error {
+	s.rows = append(s.rows, row)
+	return nil
+}
+
+func (s *xlsxSink) Close() error {
+	zw := zip.NewWriter(s.w)
+
+	write := func(name, content string) error {
+		f, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(f, content)
+		return err
+	}
+
+	if err := write("[Content_Types].xml", xlsxContentTypes); err != nil {
+		return err
+	}
+	if err := write("_rels/.rels", xlsxRootRels); err != nil {
+		return err
+	}
+	if err := write("xl/workbook.xml", xlsxWorkbook); err != nil {
+		return err
+	}
+	if err := write("xl/_rels/workbook.xml.rels", xlsxWorkbookRels); err != nil {
+		return err
+	}
+	if err := write("xl/worksheets/sheet1.xml", s.sheetXML()); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+func (s *xlsxSink) sheetXML() string {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	writeRow := func(cells []string) {
+		buf.WriteString("<row>")
+		for _, c := range cells {
+			buf.WriteString(`<c t="inlineStr"><is><t>`)
+			xml.EscapeText(&buf, []byte(c))
+			buf.WriteString(`</t></is></c>`)
+		}
+		buf.WriteString("</row>")
+	}
+
+	writeRow(s.header)
+	for _, r := range s.rows {
+		writeRow(r)
+	}
+	buf.WriteString(`</sheetData></worksheet>`)
+	return buf.String()
+}
+
+const xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+</Types>`
+
+const xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`
+
+const xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`
+
+const xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+</Relationships>`
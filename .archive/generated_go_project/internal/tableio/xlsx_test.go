@@ -0,0 +1,90 @@
+package tableio
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+)
+
+func TestXLSXColumnIndex(t *testing.T) {
+	cases := map[string]int{
+		"A1":   0,
+		"B2":   1,
+		"C3":   2,
+		"Z1":   25,
+		"AA10": 26,
+		"AB1":  27,
+	}
+	for ref, want := range cases {
+		if got := xlsxColumnIndex(ref); got != want {
+			t.Errorf("xlsxColumnIndex(%q) = %d, want %d", ref, got, want)
+		}
+	}
+}
+
+// writeMinimalXLSX builds a single-sheet xlsx with inline strings whose
+// cells follow sheetXML, letting the test omit <c> elements for blank
+// cells the way real writers do.
+func writeMinimalXLSX(t *testing.T, rows []string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	write := func(name, content string) {
+		f, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("Create(%s): %v", name, err)
+		}
+		if _, err := f.Write([]byte(content)); err != nil {
+			t.Fatalf("Write(%s): %v", name, err)
+		}
+	}
+	write("[Content_Types].xml", xlsxContentTypes)
+	write("_rels/.rels", xlsxRootRels)
+	write("xl/workbook.xml", xlsxWorkbook)
+	write("xl/_rels/workbook.xml.rels", xlsxWorkbookRels)
+	sheet := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	for _, r := range rows {
+		sheet += r
+	}
+	sheet += `</sheetData></worksheet>`
+	write("xl/worksheets/sheet1.xml", sheet)
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zw.Close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestXLSXSourceSkipsBlankCellsByRef(t *testing.T) {
+	data := writeMinimalXLSX(t, []string{
+		`<row><c r="A1" t="inlineStr"><is><t>a</t></is></c><c r="B1" t="inlineStr"><is><t>b</t></is></c><c r="C1" t="inlineStr"><is><t>c</t></is></c></row>`,
+		`<row><c r="A2" t="inlineStr"><is><t>1</t></is></c><c r="C2" t="inlineStr"><is><t>3</t></is></c></row>`,
+	})
+
+	src, err := newXLSXSource(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("newXLSXSource: %v", err)
+	}
+	if got, want := src.Header(), []string{"a", "b", "c"}; !equalStrings(got, want) {
+		t.Errorf("Header = %v, want %v", got, want)
+	}
+	row, err := src.Next()
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if want := []string{"1", "", "3"}; !equalStrings(row, want) {
+		t.Errorf("row = %v, want %v", row, want)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -1,101 +1,121 @@
 package main
 
 import (
-	"encoding/csv"
+	"flag"
 	"fmt"
-	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
+
+	"generated_go_project/internal/expr"
+	"generated_go_project/internal/sqlengine"
 )
 
 // Config holds application configuration
 type Config struct {
 	Verbose bool
+
+	// Workers is the number of concurrent transform workers ProcessCSV
+	// uses. Defaults to runtime.NumCPU() when <= 0.
+	Workers int
+	// BufferSize is the capacity of the channels connecting the reader,
+	// workers and writer stages of ProcessCSV.
+	BufferSize int
+	// PreserveOrder, when true (the default), makes ProcessCSV restore
+	// input order even though workers may finish out of order.
+	PreserveOrder bool
+
+	// InputEncoding and OutputEncoding name the source/destination text
+	// encoding (see internal/charset.Parse). An empty InputEncoding
+	// means ProcessCSV autodetects it from the first few KB of the
+	// file; an empty OutputEncoding means UTF-8.
+	InputEncoding  string
+	OutputEncoding string
+
+	// Expr, when non-empty, is an AWK-style --expr script (see
+	// internal/expr) that replaces the default uppercase transform.
+	Expr string
+
+	// InputFormat and OutputFormat name a tableio.Format (csv, tsv,
+	// json, jsonl, xlsx, parquet). Empty means detect from the input
+	// or output file's extension.
+	InputFormat  string
+	OutputFormat string
+
+	// SQL, when non-empty, is a SELECT statement (see internal/sqlengine)
+	// run over the input table in place of the transformRecord/ProcessCSV
+	// pipeline; its FROM clause names the table conventionally but is
+	// otherwise ignored, since there is exactly one input.
+	SQL string
+
+	// TypedDemo, when true, routes input/output through ProcessCSVTyped
+	// and DemoRecord (see typed_csv.go) instead of ProcessCSV, decoding
+	// each row into a struct via its `csv` tags before re-encoding it.
+	// It exists to keep the struct-tagged typed pipeline reachable from
+	// the CLI; callers with their own schema call ProcessCSVTyped
+	// directly with their own type parameter.
+	TypedDemo bool
 }
 
 // App represents the CSV processor application
 type App struct {
 	config Config
+	expr   *expr.Interp
 }
 
-// NewApp creates a new App instance
+// NewApp creates a new App instance with default streaming settings.
 func NewApp(verbose bool) *App {
-	return &App{
-		config: Config{
-			Verbose: verbose,
-		},
-	}
+	app, _ := NewAppWithConfig(Config{Verbose: verbose, PreserveOrder: true})
+	return app
 }
 
-// ProcessCSV reads input CSV, transforms records, and writes output
-func (app *App) ProcessCSV(inputPath, outputPath string) (int, error) {
-	// Open input file
-	inputFile, err := os.Open(inputPath)
-	if err != nil {
-		return 0, fmt.Errorf("failed to open input file '%s': %w", inputPath, err)
+// NewAppWithConfig creates a new App instance, filling in zero-valued
+// streaming settings (Workers, BufferSize) with sensible defaults,
+// defaulting PreserveOrder to true, and compiling cfg.Expr if set.
+func NewAppWithConfig(cfg Config) (*App, error) {
+	if cfg.Workers <= 0 {
+		cfg.Workers = runtime.NumCPU()
 	}
-	defer inputFile.Close()
-
-	// Create output file
-	outputFile, err := os.Create(outputPath)
-	if err != nil {
-		return 0, fmt.Errorf("failed to create output file '%s': %w", outputPath, err)
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = 64
 	}
-	defer outputFile.Close()
-
-	// Setup CSV readers and writers
-	reader := csv.NewReader(inputFile)
-	writer := csv.NewWriter(outputFile)
-	
-	// Configure reader (adjust as needed)
-	reader.FieldsPerRecord = -1  // Allow variable fields
-	reader.LazyQuotes = true     // Allow flexible quotes
-	reader.TrimLeadingSpace = true
-
-	var lineCount int
-
-	for {
-		// Read record
-		record, err := reader.Read()
-		if err == io.EOF {
-			break
-		}
+	app := &App{config: cfg}
+	if cfg.Expr != "" {
+		prog, err := expr.Parse(cfg.Expr)
 		if err != nil {
-			log.Printf("Warning: error reading line %d: %v", lineCount+1, err)
-			continue
+			return nil, fmt.Errorf("invalid --expr: %w", err)
 		}
-
-		// TODO: Implement custom transformation logic
-		transformed := app.transformRecord(record)
-
-		// Write transformed record
-		if err := writer.Write(transformed); err != nil {
-			return lineCount, fmt.Errorf("failed to write record: %w", err)
+		interp, err := expr.NewInterp(prog)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --expr: %w", err)
 		}
-
-		lineCount++
+		app.expr = interp
 	}
-
-	// Flush any buffered data
-	writer.Flush()
-	if err := writer.Error(); err != nil {
-		return lineCount, fmt.Errorf("failed to flush output: %w", err)
-	}
-
-	return lineCount, nil
+	return app, nil
 }
 
-// transformRecord applies transformation to a single CSV record
-func (app *App) transformRecord(record []string) []string {
-	transformed := make([]string, len(record))
+// transformRecord applies the configured transformation to a single CSV
+// record. keep reports whether the record should be written to the
+// output at all: it is always true for the default uppercase transform,
+// but an --expr script can drop a record with `next`. The default
+// transform is just the builtin upper() from internal/sqlengine's
+// function registry, trimmed first, so --sql and the default pipeline
+// share one definition of "uppercase".
+func (app *App) transformRecord(record []string, nr int) (transformed []string, keep bool, err error) {
+	if app.expr != nil {
+		return app.expr.Run(record, nr)
+	}
+	out := make([]string, len(record))
 	for i, field := range record {
-		// TODO: Customize transformation logic here
-		// Currently implements uppercase transformation
-		transformed[i] = strings.ToUpper(strings.TrimSpace(field))
+		upper, err := sqlengine.CallScalar("upper", strings.TrimSpace(field))
+		if err != nil {
+			return nil, false, err
+		}
+		out[i] = upper
 	}
-	return transformed
+	return out, true, nil
 }
 
 // run executes the main application logic
@@ -105,8 +125,18 @@ func (app *App) run(inputPath, outputPath string) error {
 		return fmt.Errorf("input file '%s' not found", inputPath)
 	}
 
-	// Process CSV
-	count, err := app.ProcessCSV(inputPath, outputPath)
+	// Process CSV, or run a --sql query over it in place of the usual
+	// transformRecord pipeline.
+	var count int
+	var err error
+	switch {
+	case app.config.SQL != "":
+		count, err = app.RunSQL(inputPath, outputPath)
+	case app.config.TypedDemo:
+		count, err = ProcessCSVTyped[DemoRecord](app, inputPath, outputPath, demoPipeline())
+	default:
+		count, err = app.ProcessCSV(inputPath, outputPath)
+	}
 	if err != nil {
 		return err
 	}
@@ -124,18 +154,39 @@ func (app *App) run(inputPath, outputPath string) error {
 }
 
 func main() {
-	// TODO: Parse command line arguments
-	// For now, hardcode example values
-	inputPath := "input.csv"
-	outputPath := "output.csv"
-	verbose := false
-
-	app := NewApp(verbose)
-
-	if err := app.run(inputPath, outputPath); err != nil {
+	inputPath := flag.String("input", "input.csv", "input CSV file")
+	outputPath := flag.String("output", "output.csv", "output CSV file")
+	verbose := flag.Bool("verbose", false, "enable verbose output")
+	workers := flag.Int("workers", runtime.NumCPU(), "number of concurrent transform workers")
+	buffer := flag.Int("buffer", 64, "channel buffer size between pipeline stages")
+	preserveOrder := flag.Bool("preserve-order", true, "preserve input record order in the output")
+	inputEncoding := flag.String("input-encoding", "", "source text encoding (default: autodetect)")
+	outputEncoding := flag.String("output-encoding", "", "destination text encoding (default: utf-8)")
+	exprScript := flag.String("expr", "", "AWK-style record transform script, e.g. '$3 = toupper($3); if ($2 ~ /^err/) next; print'")
+	inputFormat := flag.String("input-format", "", "input table format: csv, tsv, json, jsonl, xlsx, parquet (default: detect from extension)")
+	outputFormat := flag.String("output-format", "", "output table format: csv, tsv, json, jsonl, xlsx, parquet (default: detect from extension)")
+	sqlQuery := flag.String("sql", "", "run a SELECT ... FROM <table> query over the input instead of the default transform, e.g. \"select name, count(*) from t group by name\"")
+	typedDemo := flag.Bool("typed-demo", false, "process input through the struct-tagged typed pipeline (see DemoRecord in typed_csv.go) instead of the default transform; expects a 'name,amount' CSV")
+	flag.Parse()
+
+	app, err := NewAppWithConfig(Config{
+		Verbose:        *verbose,
+		Workers:        *workers,
+		BufferSize:     *buffer,
+		PreserveOrder:  *preserveOrder,
+		InputEncoding:  *inputEncoding,
+		OutputEncoding: *outputEncoding,
+		Expr:           *exprScript,
+		InputFormat:    *inputFormat,
+		OutputFormat:   *outputFormat,
+		SQL:            *sqlQuery,
+		TypedDemo:      *typedDemo,
+	})
+	if err != nil {
 		log.Fatalf("Error: %v", err)
-		os.Exit(1)
 	}
 
-	os.Exit(0)
+	if err := app.run(*inputPath, *outputPath); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
 }
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+
+	"generated_go_project/internal/sqlengine"
+)
+
+// RunSQL evaluates app.config.SQL (a SELECT ... FROM <anything> statement
+// parsed by internal/sqlengine; the FROM table name is ignored since
+// there is exactly one input) against inputPath and writes the result to
+// outputPath. It goes through the same Source/Sink and charset machinery
+// as ProcessCSV (see openSourceAndSink), but replaces the
+// producer/worker-pool/writer transform pipeline with sqlengine.Execute.
+func (app *App) RunSQL(inputPath, outputPath string) (int, error) {
+	query, err := sqlengine.Parse(app.config.SQL)
+	if err != nil {
+		return 0, fmt.Errorf("invalid --sql: %w", err)
+	}
+
+	source, sink, closeFiles, err := app.openSourceAndSink(inputPath, outputPath)
+	if err != nil {
+		return 0, err
+	}
+	defer closeFiles()
+	defer source.Close()
+
+	count, err := sqlengine.Execute(source, query, sink)
+	if err != nil {
+		return count, err
+	}
+
+	if err := sink.Close(); err != nil {
+		return count, fmt.Errorf("failed to flush output: %w", err)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunSQLSelectWhere(t *testing.T) {
+	dir := t.TempDir()
+	inputPath := filepath.Join(dir, "input.csv")
+	outputPath := filepath.Join(dir, "output.csv")
+	if err := os.WriteFile(inputPath, []byte("name,amount\nalice,5\nbob,20\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	app, err := NewAppWithConfig(Config{SQL: "select name from t where amount > 10"})
+	if err != nil {
+		t.Fatalf("NewAppWithConfig: %v", err)
+	}
+	count, err := app.RunSQL(inputPath, outputPath)
+	if err != nil {
+		t.Fatalf("RunSQL: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("count = %d, want 1", count)
+	}
+
+	out, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	want := "name\nbob\n"
+	if string(out) != want {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+}
+
+func TestOpenSourceAndSinkMissingInput(t *testing.T) {
+	dir := t.TempDir()
+	app := NewApp(false)
+	_, _, _, err := app.openSourceAndSink(filepath.Join(dir, "missing.csv"), filepath.Join(dir, "output.csv"))
+	if err == nil {
+		t.Error("openSourceAndSink: expected error for missing input file, got nil")
+	}
+}
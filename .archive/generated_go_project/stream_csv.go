@@ -0,0 +1,308 @@
+package main
+
+import (
+	"bufio"
+	"container/heap"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"generated_go_project/internal/charset"
+	"generated_go_project/internal/tableio"
+)
+
+// encodingSampleSize is how much of the input file is peeked for
+// encoding autodetection.
+const encodingSampleSize = 4096
+
+// record pairs a row with its input sequence number so the writer stage
+// can restore input order even though workers may finish out of order.
+type record struct {
+	seq  int
+	row  []string
+	keep bool
+}
+
+// recordHeap is a min-heap of records ordered by seq, used by the writer
+// stage to buffer out-of-order worker output until the next record in
+// sequence is available.
+type recordHeap []record
+
+func (h recordHeap) Len() int            { return len(h) }
+func (h recordHeap) Less(i, j int) bool  { return h[i].seq < h[j].seq }
+func (h recordHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *recordHeap) Push(x interface{}) { *h = append(*h, x.(record)) }
+func (h *recordHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
+
+// ProcessCSV reads inputPath, transforms records, and writes outputPath.
+// Reading and writing go through the Source/Sink abstraction in
+// internal/tableio, so inputPath and outputPath may be any supported
+// format (CSV, TSV, JSON, JSON Lines, XLSX); the format is taken from
+// app.config.InputFormat/OutputFormat or, if unset, from each path's
+// extension. The pipeline itself is a producer/worker-pool/writer
+// arrangement: one goroutine reads rows into a bounded channel,
+// app.config.Workers goroutines apply transformRecord concurrently, and
+// the writer goroutine drains the results, restoring input order unless
+// app.config.PreserveOrder is false.
+func (app *App) ProcessCSV(inputPath, outputPath string) (int, error) {
+	source, sink, closeFiles, err := app.openSourceAndSink(inputPath, outputPath)
+	if err != nil {
+		return 0, err
+	}
+	defer closeFiles()
+	defer source.Close()
+
+	header := source.Header()
+	if err := sink.WriteHeader(header); err != nil {
+		return 0, fmt.Errorf("failed to write header: %w", err)
+	}
+
+	buffer := app.config.BufferSize
+	if buffer <= 0 {
+		buffer = 1
+	}
+	workers := app.config.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+
+	in := make(chan record, buffer)
+	out := make(chan record, buffer)
+	// stop is closed once the writer stage gives up (e.g. sink.Write
+	// fails), so the producer and workers can unblock from sends that
+	// would otherwise never be drained and return instead of leaking.
+	stop := make(chan struct{})
+
+	// Producer: read records and hand them to the worker pool in order.
+	go func() {
+		defer close(in)
+		seq := 0
+		for {
+			row, err := source.Next()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				log.Printf("Warning: error reading record %d: %v", seq+1, err)
+				continue
+			}
+			select {
+			case in <- record{seq: seq, row: row, keep: true}:
+				seq++
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	// Workers: transform records concurrently; order is not preserved here.
+	done := make(chan struct{}, workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer func() { done <- struct{}{} }()
+			for r := range in {
+				transformed, keep, err := app.transformRecord(r.row, r.seq+1)
+				if err != nil {
+					log.Printf("Warning: error transforming record %d: %v", r.seq+1, err)
+					r.keep = false
+				} else {
+					r.row = transformed
+					r.keep = keep
+				}
+				select {
+				case out <- r:
+				case <-stop:
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		for i := 0; i < workers; i++ {
+			<-done
+		}
+		close(out)
+	}()
+
+	// Writer: drain results, restoring input order unless disabled.
+	var lineCount int
+	if app.config.PreserveOrder {
+		lineCount, err = writeOrdered(sink, out, stop)
+	} else {
+		lineCount, err = writeUnordered(sink, out, stop)
+	}
+	if err != nil {
+		return lineCount, err
+	}
+
+	if err := sink.Close(); err != nil {
+		return lineCount, fmt.Errorf("failed to flush output: %w", err)
+	}
+
+	return lineCount, nil
+}
+
+// resolveFormat picks explicit over detected-from-extension.
+func (app *App) resolveFormat(explicit, path string) (tableio.Format, error) {
+	if explicit != "" {
+		return tableio.ParseFormat(explicit)
+	}
+	return tableio.DetectFormat(path)
+}
+
+// openSourceAndSink opens inputPath/outputPath, resolves their table
+// formats (app.config.InputFormat/OutputFormat, or each path's
+// extension), and wraps each file in the charset transcoding and
+// tableio.Source/Sink layers. It is the shared setup ProcessCSV and
+// RunSQL both need before picking their own execution strategy. The
+// returned closeFiles closes the underlying os.File handles; callers are
+// still responsible for closing the returned source and, after writing,
+// the returned sink.
+func (app *App) openSourceAndSink(inputPath, outputPath string) (tableio.Source, tableio.Sink, func() error, error) {
+	inputFile, err := os.Open(inputPath)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to open input file '%s': %w", inputPath, err)
+	}
+	outputFile, err := os.Create(outputPath)
+	if err != nil {
+		inputFile.Close()
+		return nil, nil, nil, fmt.Errorf("failed to create output file '%s': %w", outputPath, err)
+	}
+	closeFiles := func() error {
+		inputFile.Close()
+		return outputFile.Close()
+	}
+
+	inFormat, err := app.resolveFormat(app.config.InputFormat, inputPath)
+	if err != nil {
+		closeFiles()
+		return nil, nil, nil, err
+	}
+	outFormat, err := app.resolveFormat(app.config.OutputFormat, outputPath)
+	if err != nil {
+		closeFiles()
+		return nil, nil, nil, err
+	}
+
+	var inputReader io.Reader = inputFile
+	if !inFormat.IsBinary() {
+		inputReader, err = app.decodingReader(inputFile)
+		if err != nil {
+			closeFiles()
+			return nil, nil, nil, err
+		}
+	}
+	var outputWriter io.Writer = outputFile
+	if !outFormat.IsBinary() {
+		outputWriter, err = app.encodingWriter(outputFile)
+		if err != nil {
+			closeFiles()
+			return nil, nil, nil, err
+		}
+	}
+
+	source, err := tableio.NewSource(inputReader, inFormat)
+	if err != nil {
+		closeFiles()
+		return nil, nil, nil, fmt.Errorf("failed to open input as %s: %w", inFormat, err)
+	}
+
+	sink, err := tableio.NewSink(outputWriter, outFormat)
+	if err != nil {
+		source.Close()
+		closeFiles()
+		return nil, nil, nil, fmt.Errorf("failed to open output as %s: %w", outFormat, err)
+	}
+
+	return source, sink, closeFiles, nil
+}
+
+// writeUnordered writes results to sink as soon as they arrive, in
+// whatever order the worker pool produced them. If sink.Write fails, it
+// closes stop before returning so the producer and worker goroutines
+// still feeding out unblock from their sends and exit instead of
+// leaking.
+func writeUnordered(sink tableio.Sink, out <-chan record, stop chan<- struct{}) (int, error) {
+	var lineCount int
+	for r := range out {
+		if !r.keep {
+			continue
+		}
+		if err := sink.Write(r.row); err != nil {
+			close(stop)
+			return lineCount, fmt.Errorf("failed to write record: %w", err)
+		}
+		lineCount++
+	}
+	return lineCount, nil
+}
+
+// writeOrdered buffers out-of-order results in a min-heap keyed by
+// sequence number and flushes them to sink as soon as the next record in
+// sequence becomes available. If sink.Write fails, it closes stop before
+// returning so the producer and worker goroutines still feeding out
+// unblock from their sends and exit instead of leaking.
+func writeOrdered(sink tableio.Sink, out <-chan record, stop chan<- struct{}) (int, error) {
+	h := &recordHeap{}
+	heap.Init(h)
+	next := 0
+	var lineCount int
+
+	for r := range out {
+		heap.Push(h, r)
+		for h.Len() > 0 && (*h)[0].seq == next {
+			top := heap.Pop(h).(record)
+			if top.keep {
+				if err := sink.Write(top.row); err != nil {
+					close(stop)
+					return lineCount, fmt.Errorf("failed to write record: %w", err)
+				}
+				lineCount++
+			}
+			next++
+		}
+	}
+	return lineCount, nil
+}
+
+// decodingReader wraps src with a transcoder from app.config.InputEncoding
+// into UTF-8. When InputEncoding is unset it peeks the first few KB of
+// src to autodetect the encoding.
+func (app *App) decodingReader(src io.Reader) (io.Reader, error) {
+	if app.config.InputEncoding != "" {
+		enc, err := charset.Parse(app.config.InputEncoding)
+		if err != nil {
+			return nil, err
+		}
+		return charset.NewReader(src, enc)
+	}
+
+	buffered := bufio.NewReaderSize(src, encodingSampleSize)
+	sample, _ := buffered.Peek(encodingSampleSize)
+	enc := charset.Detect(sample)
+	if enc != charset.UTF8 {
+		// Always surfaced, not just under --verbose: byte-pattern
+		// detection can't tell GBK from Shift-JIS, so if Detect guessed
+		// wrong the user needs this line to know --input-encoding is
+		// there to override it.
+		log.Printf("detected input encoding: %s", enc)
+	}
+	return charset.NewReader(buffered, enc)
+}
+
+// encodingWriter wraps dst with a transcoder from UTF-8 into
+// app.config.OutputEncoding, defaulting to UTF-8 (no transcoding).
+func (app *App) encodingWriter(dst io.Writer) (io.Writer, error) {
+	enc, err := charset.Parse(app.config.OutputEncoding)
+	if err != nil {
+		return nil, err
+	}
+	return charset.NewWriter(dst, enc)
+}
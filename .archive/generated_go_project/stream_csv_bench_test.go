@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// genCSV writes an n-row "id,value" CSV to dir and returns its path.
+func genCSV(tb testing.TB, dir string, n int) string {
+	tb.Helper()
+	var b strings.Builder
+	b.WriteString("id,value\n")
+	for i := 0; i < n; i++ {
+		b.WriteString(strconv.Itoa(i))
+		b.WriteByte(',')
+		b.WriteString("row-value-")
+		b.WriteString(strconv.Itoa(i))
+		b.WriteByte('\n')
+	}
+	path := filepath.Join(dir, "bench_input.csv")
+	if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+		tb.Fatalf("WriteFile: %v", err)
+	}
+	return path
+}
+
+// BenchmarkProcessCSVWorkers processes a fixed-size input with a varying
+// worker-pool size, approximating the throughput-scaling-with-concurrency
+// behavior ProcessCSV's producer/worker-pool/writer design is meant to
+// provide. It runs against an in-memory-sized fixture rather than an
+// actual multi-GB file, since a benchmark suite that writes multiple GB
+// to disk on every `go test -bench` run isn't practical; the scaling
+// trend it measures (more workers raising rows/sec up to NumCPU) is the
+// same one that matters at multi-GB scale.
+func BenchmarkProcessCSVWorkers(b *testing.B) {
+	dir := b.TempDir()
+	inputPath := genCSV(b, dir, 20000)
+
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			outputPath := filepath.Join(dir, fmt.Sprintf("bench_output_%d.csv", workers))
+			app, err := NewAppWithConfig(Config{Workers: workers, BufferSize: 64, PreserveOrder: true})
+			if err != nil {
+				b.Fatalf("NewAppWithConfig: %v", err)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := app.ProcessCSV(inputPath, outputPath); err != nil {
+					b.Fatalf("ProcessCSV: %v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestProcessCSVUppercasesDefault(t *testing.T) {
+	dir := t.TempDir()
+	inputPath := filepath.Join(dir, "input.csv")
+	outputPath := filepath.Join(dir, "output.csv")
+	if err := os.WriteFile(inputPath, []byte("name,note\nalice, hi \n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	app := NewApp(false)
+	count, err := app.ProcessCSV(inputPath, outputPath)
+	if err != nil {
+		t.Fatalf("ProcessCSV: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("count = %d, want 1", count)
+	}
+
+	out, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	want := "name,note\nALICE,HI\n"
+	if string(out) != want {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+}
+
+func TestProcessCSVPreservesOrderAcrossWorkers(t *testing.T) {
+	dir := t.TempDir()
+	inputPath := filepath.Join(dir, "input.csv")
+	outputPath := filepath.Join(dir, "output.csv")
+	if err := os.WriteFile(inputPath, []byte("n\n1\n2\n3\n4\n5\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	app, err := NewAppWithConfig(Config{Workers: 4, BufferSize: 1, PreserveOrder: true})
+	if err != nil {
+		t.Fatalf("NewAppWithConfig: %v", err)
+	}
+	if _, err := app.ProcessCSV(inputPath, outputPath); err != nil {
+		t.Fatalf("ProcessCSV: %v", err)
+	}
+
+	out, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	want := "n\n1\n2\n3\n4\n5\n"
+	if string(out) != want {
+		t.Errorf("output = %q, want %q (order not preserved)", out, want)
+	}
+}
+
+// failingSink fails every Write, simulating a broken output (e.g. a
+// full disk), so writeOrdered/writeUnordered's error path can be tested.
+type failingSink struct{}
+
+func (failingSink) WriteHeader([]string) error { return nil }
+func (failingSink) Write([]string) error       { return errors.New("write failed") }
+func (failingSink) Close() error               { return nil }
+
+// feedUntilStop simulates a worker goroutine that keeps sending records
+// to out until stop is closed, then exits and closes done. Used to
+// verify writeOrdered/writeUnordered unblock such a sender instead of
+// leaking it when sink.Write fails.
+func feedUntilStop(out chan<- record, stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	for seq := 0; ; seq++ {
+		select {
+		case out <- record{seq: seq, row: []string{"x"}, keep: true}:
+		case <-stop:
+			return
+		}
+	}
+}
+
+func TestWriteOrderedUnblocksSenderOnWriteError(t *testing.T) {
+	out := make(chan record, 1)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go feedUntilStop(out, stop, done)
+
+	if _, err := writeOrdered(failingSink{}, out, stop); err == nil {
+		t.Fatal("writeOrdered: expected error, got nil")
+	}
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("sender goroutine leaked: did not exit after writeOrdered returned an error")
+	}
+}
+
+func TestWriteUnorderedUnblocksSenderOnWriteError(t *testing.T) {
+	out := make(chan record, 1)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go feedUntilStop(out, stop, done)
+
+	if _, err := writeUnordered(failingSink{}, out, stop); err == nil {
+		t.Fatal("writeUnordered: expected error, got nil")
+	}
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("sender goroutine leaked: did not exit after writeUnordered returned an error")
+	}
+}
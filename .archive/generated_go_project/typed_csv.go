@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+
+	"generated_go_project/internal/pipeline"
+)
+
+// DemoRecord is the schema ProcessCSVTyped is exercised against by the
+// --typed-demo flag: a minimal struct-tagged record with one string and
+// one numeric column, put through a Pipeline that uppercases the name and
+// rounds the amount to two decimal places. Callers with their own CSV
+// schema use ProcessCSVTyped directly with their own struct type; this
+// one exists only to keep the typed path reachable and tested from the
+// built CLI.
+type DemoRecord struct {
+	Name   string  `csv:"name"`
+	Amount float64 `csv:"amount"`
+}
+
+// demoPipeline builds the Pipeline used by --typed-demo.
+func demoPipeline() *pipeline.Pipeline {
+	p := pipeline.New()
+	p.Column("name").Upper()
+	p.Column("amount").Numeric().Round(2)
+	return p
+}
+
+// ProcessCSVTyped reads inputPath into values of type T using their `csv`
+// struct tags, applies p to each decoded row (keyed by column name), and
+// writes the result to outputPath using the same header.
+//
+// Go does not allow a method to introduce its own type parameter, so this
+// lives as a free function taking *App rather than as App.ProcessCSVTyped
+// directly; callers read it as App's typed counterpart to ProcessCSV.
+func ProcessCSVTyped[T any](app *App, inputPath, outputPath string, p *pipeline.Pipeline) (int, error) {
+	inputFile, err := os.Open(inputPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open input file '%s': %w", inputPath, err)
+	}
+	defer inputFile.Close()
+
+	outputFile, err := os.Create(outputPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create output file '%s': %w", outputPath, err)
+	}
+	defer outputFile.Close()
+
+	reader := csv.NewReader(inputFile)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	writer := csv.NewWriter(outputFile)
+	if err := writer.Write(header); err != nil {
+		return 0, fmt.Errorf("failed to write header: %w", err)
+	}
+
+	var lineCount int
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if app.config.Verbose {
+				fmt.Fprintf(os.Stderr, "Warning: error reading line %d: %v\n", lineCount+2, err)
+			}
+			continue
+		}
+
+		row, err := pipeline.DecodeRow[T](header, record)
+		if err != nil {
+			return lineCount, fmt.Errorf("line %d: decode: %w", lineCount+2, err)
+		}
+
+		fields := make(map[string]string, len(header))
+		encoded, err := pipeline.EncodeRow(header, row)
+		if err != nil {
+			return lineCount, fmt.Errorf("line %d: encode: %w", lineCount+2, err)
+		}
+		for i, col := range header {
+			if i < len(encoded) {
+				fields[col] = encoded[i]
+			}
+		}
+
+		if p != nil {
+			fields, err = p.Apply(fields)
+			if err != nil {
+				return lineCount, fmt.Errorf("line %d: transform: %w", lineCount+2, err)
+			}
+		}
+
+		out := make([]string, len(header))
+		for i, col := range header {
+			out[i] = fields[col]
+		}
+
+		if err := writer.Write(out); err != nil {
+			return lineCount, fmt.Errorf("failed to write record: %w", err)
+		}
+		lineCount++
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return lineCount, fmt.Errorf("failed to flush output: %w", err)
+	}
+
+	return lineCount, nil
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProcessCSVTypedDemo(t *testing.T) {
+	dir := t.TempDir()
+	inputPath := filepath.Join(dir, "input.csv")
+	outputPath := filepath.Join(dir, "output.csv")
+
+	if err := os.WriteFile(inputPath, []byte("name,amount\nalice,1.239\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	app := NewApp(false)
+	count, err := ProcessCSVTyped[DemoRecord](app, inputPath, outputPath, demoPipeline())
+	if err != nil {
+		t.Fatalf("ProcessCSVTyped: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("count = %d, want 1", count)
+	}
+
+	out, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	want := "name,amount\nALICE,1.24\n"
+	if string(out) != want {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+}